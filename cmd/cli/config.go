@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/authorizer/internal/engine"
+)
+
+// validateConfig check every rules configuration file given and report the rules each one
+// describes, the error counts the files found invalid
+func validateConfig(args []string) error {
+	flags := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: authorizer validate-config FILE...")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		return errors.New("validate-config: at least one rules configuration file is required")
+	}
+
+	invalid := 0
+
+	for _, path := range flags.Args() {
+		rules, err := engine.LoadRules(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			invalid++
+			continue
+		}
+
+		fmt.Printf("%s: %d rules\n", path, len(rules))
+
+		for _, rule := range rules {
+			fmt.Printf("  %s: %s, %d every %s, %s\n", rule.Name, rule.Type, rule.UsageLimit, rule.Accumulator.Duration, rule.RuleViolation)
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d of %d rules configurations are invalid", invalid, flags.NArg())
+	}
+
+	return nil
+}
@@ -1,28 +1,213 @@
 package main
 
 import (
-	"github.com/authorizer/internal/core/service"
-	"github.com/authorizer/internal/driven/database"
-	"github.com/authorizer/internal/driven/repository"
+	"flag"
+	"fmt"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/logging"
+	"github.com/authorizer/internal/driven/metrics"
 	"github.com/authorizer/internal/driver/cli"
-	"log"
+	"github.com/authorizer/internal/driver/jsonrpc"
+	"github.com/authorizer/internal/engine"
+	"io"
 	"os"
+	"strings"
 )
 
+// Formats the operations are read and written in by run
+const (
+	jsonFormat    = "json"
+	jsonRPCFormat = "jsonrpc"
+)
+
+// command is a subcommand of the authorizer
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "run", usage: "process the operations of a stream, the standard input by default", run: run},
+	{name: "serve", usage: "serve a protocol on a TCP or Unix socket", run: serve},
+	{name: "replay", usage: "re-run a recorded stream and report the lines whose output changed", run: replay},
+	{name: "simulate", usage: "compare candidate rules configurations over a recorded stream", run: simulate},
+	{name: "validate-config", usage: "check a rules configuration file", run: validateConfig},
+}
+
 func main() {
-	db := database.NewInMemoryDB()
+	logger := logging.NewLogger(os.Stderr, logging.InfoLevel, clock.NewSystemClock())
+
+	if err := dispatch(os.Args[1:]); err != nil {
+		logger.Error("authorizer stopped", logging.Field{Key: "error", Value: err})
+		os.Exit(1)
+	}
+}
+
+// dispatch run the subcommand named by the first argument, run when there is none so the
+// authorizer still reads the standard input when called with flags only
+func dispatch(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return run(args)
+	}
+
+	switch args[0] {
+	case "help":
+		usage(os.Stdout)
+		return nil
+	case "jsonrpc":
+		return run(append([]string{"-format", jsonRPCFormat}, args[1:]...))
+	}
+
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:])
+		}
+	}
+
+	usage(os.Stderr)
+
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+// usage list the subcommands
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: authorizer [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.usage)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "run is the default command, authorizer <command> -h lists its flags")
+}
+
+// run processes the operations read from the input stream
+func run(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	format := flags.String("format", jsonFormat, "format the operations are read and written in, json or jsonrpc")
+	inputPath := flags.String("input", "", "file the operations are read from, the standard input when empty")
+	outputPath := flags.String("output", "", "file the outputs are written to, the standard output when empty")
+	verbose := flags.Bool("verbose", false, "explain every check behind each authorization, json format only")
+	metricsPath := flags.String("metrics", "", "file the metrics are written to once the input ends, - for the standard error")
+
+	var opts engine.Options
+	opts.Register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != jsonFormat && *format != jsonRPCFormat {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	e, err := opts.Build()
+	if err != nil {
+		return err
+	}
+	defer e.CloseLog()
+
+	input, closeInput, err := openInput(*inputPath)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	output, closeOutput, err := openOutput(*outputPath)
+	if err != nil {
+		return err
+	}
+
+	stopDelivery, err := e.Delivery.Start(opts.Events)
+	if err != nil {
+		_ = closeOutput()
+		return err
+	}
+
+	err = handle(e, *format, *verbose, input, output)
+
+	stopDelivery()
+
+	if closeErr := closeOutput(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return dumpMetrics(e.Metrics, *metricsPath)
+}
+
+// handle process the input in the format with the engine
+func handle(e *engine.Engine, format string, verbose bool, r io.Reader, w io.Writer) error {
+	if format == jsonRPCFormat {
+		if err := e.RefuseReorder("jsonrpc format"); err != nil {
+			return err
+		}
+
+		return jsonrpc.NewHandler(e.Accounts, e.Transactions).Handle(r, w)
+	}
+
+	handler := cli.NewHandler(e.Accounts, e.Transactions, e.Idempotency)
+	if verbose {
+		handler = cli.NewVerboseHandler(e.Accounts, e.Transactions, e.Idempotency)
+	}
+
+	return handler.WithLogger(e.Logger).WithReorder(e.Reorder).Handle(r, w)
+}
+
+// openInput open the file at path, the standard input when it is empty
+func openInput(path string) (io.Reader, func() error, error) {
+	if path == "" {
+		return os.Stdin, func() error { return nil }, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, f.Close, nil
+}
 
-	accountRepo := repository.NewAccountRepository(db)
+// openOutput create the file at path, the standard output when it is empty
+func openOutput(path string) (io.Writer, func() error, error) {
+	if path == "" {
+		return os.Stdout, func() error { return nil }, nil
+	}
 
-	as := service.NewAccount(accountRepo)
-	ts := service.NewTransaction(accountRepo)
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	handler := cli.NewHandler(as, ts)
+	return f, f.Close, nil
+}
 
-	log.SetOutput(os.Stdout)
+// dumpMetrics write the metrics in the Prometheus text exposition format to the file at path,
+// to the standard error when path is -, nowhere when it is empty
+func dumpMetrics(m *metrics.Metrics, path string) error {
+	switch path {
+	case "":
+		return nil
+	case "-":
+		_, err := m.WriteTo(os.Stderr)
+		return err
+	}
 
-	err := handler.Handle(os.Stdin, os.Stdout)
+	f, err := os.Create(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+
+	if _, err := m.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
 	}
+
+	return f.Close()
 }
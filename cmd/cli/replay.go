@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/authorizer/internal/driver/cli"
+	"github.com/authorizer/internal/engine"
+)
+
+// replay re-runs a recorded input stream through a fresh engine and writes every line whose
+// output differs from the recorded one, or from a run with the default rules
+func replay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	inputPath := flags.String("input", "", "recorded input stream, one operation per line")
+	outputPath := flags.String("output", "", "recorded output stream to compare with, a run with the default rules when empty")
+
+	var opts engine.Options
+	opts.RegisterEvaluation(flags)
+	flags.StringVar(&opts.RulesPath, "rules", "", "rules configuration to replay the input against, the default rules when empty")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *inputPath == "" {
+		return errors.New("replay: -input is required")
+	}
+
+	// both runs number their authorizations the same way, so IDs only differ when outcomes do
+	opts.SequentialIDs = true
+
+	candidate, err := opts.Build()
+	if err != nil {
+		return err
+	}
+	defer candidate.CloseLog()
+
+	if err := candidate.RefuseReorder("replay"); err != nil {
+		return err
+	}
+
+	input, err := os.ReadFile(*inputPath)
+	if err != nil {
+		return err
+	}
+
+	var expected bytes.Buffer
+
+	if *outputPath != "" {
+		recorded, err := os.ReadFile(*outputPath)
+		if err != nil {
+			return err
+		}
+
+		expected.Write(recorded)
+	} else {
+		baselineOpts := opts
+		baselineOpts.RulesPath = ""
+
+		baseline, err := baselineOpts.Build()
+		if err != nil {
+			return err
+		}
+		defer baseline.CloseLog()
+
+		if err := newHandler(baseline).Handle(bytes.NewReader(input), &expected); err != nil {
+			return err
+		}
+	}
+
+	replayed, differences, err := newHandler(candidate).Replay(bytes.NewReader(input), &expected)
+	if err != nil {
+		return err
+	}
+
+	for _, difference := range differences {
+		jm, _ := json.Marshal(difference)
+		fmt.Println(string(jm))
+	}
+
+	if len(differences) > 0 {
+		return fmt.Errorf("%d of %d replayed lines differ", len(differences), replayed)
+	}
+
+	fmt.Fprintf(os.Stderr, "%d lines replayed, no differences\n", replayed)
+
+	return nil
+}
+
+// newHandler build the handler of the JSON operations over the engine
+func newHandler(e *engine.Engine) cli.Handler {
+	return cli.NewHandler(e.Accounts, e.Transactions, e.Idempotency).WithLogger(e.Logger)
+}
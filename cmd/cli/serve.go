@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/authorizer/internal/driven/logging"
+	"github.com/authorizer/internal/driven/metrics"
+	"github.com/authorizer/internal/driver/cli"
+	"github.com/authorizer/internal/driver/iso8583"
+	"github.com/authorizer/internal/driver/jsonrpc"
+	"github.com/authorizer/internal/driver/socket"
+	"github.com/authorizer/internal/engine"
+)
+
+// serve runs the chosen protocol on a TCP or Unix socket until SIGINT or SIGTERM
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	protocol := flags.String("protocol", "json", "protocol spoken on each connection, json, jsonrpc or iso8583")
+	network := flags.String("network", "tcp", "socket type, tcp or unix")
+	addr := flags.String("addr", ":9000", "address to listen on, a path for unix sockets")
+	drainTimeout := flags.Duration("drain-timeout", 10*time.Second, "how long to wait for open connections on shutdown")
+	verbose := flags.Bool("verbose", false, "explain every check behind each authorization, json protocol only")
+	metricsAddr := flags.String("metrics-addr", "", "address to serve the metrics on over HTTP at /metrics, none when empty")
+
+	var opts engine.Options
+	opts.Register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	e, err := opts.Build()
+	if err != nil {
+		return err
+	}
+	defer e.CloseLog()
+
+	stopDelivery, err := e.Delivery.Start(opts.Events)
+	if err != nil {
+		return err
+	}
+	defer stopDelivery()
+
+	var handler socket.Handler
+
+	switch *protocol {
+	case "json":
+		jsonHandler := cli.NewHandler(e.Accounts, e.Transactions, e.Idempotency)
+		if *verbose {
+			jsonHandler = cli.NewVerboseHandler(e.Accounts, e.Transactions, e.Idempotency)
+		}
+
+		handler = jsonHandler.WithLogger(e.Logger).WithReorder(e.Reorder)
+	case "jsonrpc":
+		handler = jsonrpc.NewHandler(e.Accounts, e.Transactions)
+	case "iso8583":
+		handler = iso8583.NewHandlerWithRules(e.Transactions, e.Clock, e.Rules)
+	default:
+		return fmt.Errorf("unknown protocol %q", *protocol)
+	}
+
+	if *protocol != "json" {
+		if err := e.RefuseReorder(*protocol + " protocol"); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen(*network, *addr)
+	if err != nil {
+		return err
+	}
+
+	if *metricsAddr != "" {
+		stopMetrics, err := serveMetrics(e.Metrics, *metricsAddr, e.Logger)
+		if err != nil {
+			return err
+		}
+		defer stopMetrics()
+	}
+
+	server := socket.NewServer(handler, e.Logger)
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	e.Logger.Info("listening", logging.Field{Key: "network", Value: *network}, logging.Field{Key: "addr", Value: listener.Addr().String()})
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *drainTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+
+	if err := <-served; !errors.Is(err, socket.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// serveMetrics answer the metrics at /metrics on addr until stop is called
+func serveMetrics(m *metrics.Metrics, addr string, logger *logging.Logger) (stop func(), err error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("metrics server failed", logging.Field{Key: "error", Value: err})
+		}
+	}()
+
+	logger.Info("serving metrics", logging.Field{Key: "addr", Value: listener.Addr().String()})
+
+	return func() { _ = server.Close() }, nil
+}
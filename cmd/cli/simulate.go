@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/driver/cli"
+	"github.com/authorizer/internal/engine"
+)
+
+// currentConfig names the configuration the candidates are compared with when it is the
+// default rules
+const currentConfig = "current"
+
+// configResult is the summary of a simulation under one rules configuration
+type configResult struct {
+	Config string `json:"config"`
+	cli.Simulation
+	Changes []cli.Change `json:"changes"`
+}
+
+// simulate runs a recorded input stream under the current rules and under every candidate
+// configuration, and writes one summary per configuration with the transactions whose
+// outcome differs from the current one
+func simulate(args []string) error {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	inputPath := flags.String("input", "", "recorded input stream, one operation per line, the standard input when empty")
+	currentPath := flags.String("current", "", "rules configuration in use today, the default rules when empty")
+
+	var opts engine.Options
+	opts.RegisterEvaluation(flags)
+	// every run numbers its authorizations the same way
+	opts.SequentialIDs = true
+
+	var candidates []string
+	flags.Func("rules", "candidate rules configuration, repeat the flag for several", func(path string) error {
+		candidates = append(candidates, path)
+		return nil
+	})
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if len(candidates) == 0 {
+		return errors.New("simulate: at least one -rules is required")
+	}
+
+	currentName, currentRules := currentConfig, domain.DefaultRules()
+
+	if *currentPath != "" {
+		var err error
+		if currentRules, err = engine.LoadRules(*currentPath); err != nil {
+			return err
+		}
+
+		currentName = *currentPath
+	}
+
+	candidateRules := make([][]domain.Rule, 0, len(candidates))
+
+	for _, path := range candidates {
+		rules, err := engine.LoadRules(path)
+		if err != nil {
+			return err
+		}
+
+		candidateRules = append(candidateRules, rules)
+	}
+
+	r, closeInput, err := openInput(*inputPath)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	current, err := simulateWith(opts, currentRules, input)
+	if err != nil {
+		return err
+	}
+
+	results := []configResult{{Config: currentName, Simulation: current, Changes: make([]cli.Change, 0)}}
+
+	for i, rules := range candidateRules {
+		simulation, err := simulateWith(opts, rules, input)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, configResult{Config: candidates[i], Simulation: simulation, Changes: simulation.Changes(current)})
+	}
+
+	for _, result := range results {
+		jm, _ := json.Marshal(result)
+		fmt.Println(string(jm))
+	}
+
+	return nil
+}
+
+// simulateWith run the input through a fresh engine of the options whose accounts are created
+// with the rules
+func simulateWith(opts engine.Options, rules []domain.Rule, input []byte) (cli.Simulation, error) {
+	opts.Rules = rules
+
+	e, err := opts.Build()
+	if err != nil {
+		return cli.Simulation{}, err
+	}
+	defer e.CloseLog()
+
+	if err := e.RefuseReorder("simulate"); err != nil {
+		return cli.Simulation{}, err
+	}
+
+	return newHandler(e).Simulate(bytes.NewReader(input))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/logging"
+	httpdriver "github.com/authorizer/internal/driver/http"
+	"github.com/authorizer/internal/engine"
+)
+
+func main() {
+	logger := logging.NewLogger(os.Stderr, logging.InfoLevel, clock.NewSystemClock())
+
+	if err := serve(os.Args[1:]); err != nil {
+		logger.Error("authorizer stopped", logging.Field{Key: "error", Value: err})
+		os.Exit(1)
+	}
+}
+
+// serve answers the HTTP API until SIGINT or SIGTERM
+func serve(args []string) error {
+	flags := flag.NewFlagSet("http", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+
+	var opts engine.Options
+	opts.Register(flags)
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	e, err := opts.Build()
+	if err != nil {
+		return err
+	}
+	defer e.CloseLog()
+
+	if err := e.RefuseReorder("http"); err != nil {
+		return err
+	}
+
+	stopDelivery, err := e.Delivery.Start(opts.Events)
+	if err != nil {
+		return err
+	}
+	// deliver the events left in the outbox before the sink is closed
+	defer stopDelivery()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e.Metrics)
+	mux.Handle("/", httpdriver.NewHandler(e.Accounts, e.Transactions))
+
+	server := &http.Server{
+		Addr:     *addr,
+		Handler:  mux,
+		ErrorLog: log.New(e.Logger.Writer(logging.WarnLevel), "", 0),
+	}
+
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	drained := make(chan error, 1)
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		drained <- server.Shutdown(shutdownCtx)
+	}()
+
+	e.Logger.Info("listening", logging.Field{Key: "addr", Value: *addr})
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return <-drained
+}
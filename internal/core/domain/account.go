@@ -1,5 +1,8 @@
 package domain
 
+// DefaultAccountID identifies the single account handled by the authorizer
+const DefaultAccountID int64 = 1
+
 type SpendingControl struct {
 	Rules []Rule
 }
@@ -7,5 +10,45 @@ type SpendingControl struct {
 type Account struct {
 	Ledger          Ledger
 	SpendingControl SpendingControl
-	Authorizations  []TransactionAuthorization
+}
+
+// Apply fold the event into the account. AccountCreated sets the account up, TransactionApproved
+// spends the amount from the ledger and on every rule, in the rule period at the transaction
+// time, the way the authorization does. Other events leave the account as it is
+func (a *Account) Apply(event Event) {
+	switch e := event.(type) {
+	case AccountCreated:
+		rules := make([]Rule, 0, len(e.Rules))
+
+		for _, rule := range e.Rules {
+			if rule.Accumulator != nil {
+				accumulator := *rule.Accumulator
+				rule.Accumulator = &accumulator
+			}
+
+			rules = append(rules, rule)
+		}
+
+		a.Ledger = e.Ledger
+		a.SpendingControl = SpendingControl{Rules: rules}
+	case TransactionApproved:
+		a.Ledger.AvailableLimit = a.Ledger.AvailableLimit - e.Amount
+
+		for i, rule := range a.SpendingControl.Rules {
+			if rule.Accumulator == nil {
+				continue
+			}
+
+			accumulator := BuildAccumulator(
+				e.Time,
+				rule.Accumulator.Duration,
+				rule.Accumulator.CurrentPeriodUsed,
+				rule.Accumulator.CurrentPeriodSpend,
+				rule.Accumulator.PeriodEndsDate,
+			)
+			accumulator.AddSpend(e.Amount)
+
+			a.SpendingControl.Rules[i].Accumulator = &accumulator
+		}
+	}
 }
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccount_Apply(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	created := AccountCreated{
+		AccountID: DefaultAccountID,
+		Ledger:    Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 100},
+		Rules: []Rule{
+			{Name: "max transactions in 2 minutes", Type: "usage-limit", UsageLimit: 3, Accumulator: &Accumulator{Duration: 2 * time.Minute}},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		events              []Event
+		expectedLimit       int64
+		expectedAccumulator Accumulator
+	}{
+		{
+			name:                "conta criada",
+			events:              []Event{created},
+			expectedLimit:       100,
+			expectedAccumulator: Accumulator{Duration: 2 * time.Minute},
+		},
+		{
+			name: "transações aprovadas no mesmo período",
+			events: []Event{
+				created,
+				TransactionApproved{Amount: 10, Time: baseTime},
+				TransactionApproved{Amount: 20, Time: baseTime.Add(time.Minute)},
+			},
+			expectedLimit: 70,
+			expectedAccumulator: Accumulator{
+				Duration:           2 * time.Minute,
+				CurrentPeriodUsed:  2,
+				CurrentPeriodSpend: 30,
+				PeriodEndsDate:     baseTime.Add(2 * time.Minute),
+			},
+		},
+		{
+			name: "transação aprovada depois do período",
+			events: []Event{
+				created,
+				TransactionApproved{Amount: 10, Time: baseTime},
+				TransactionApproved{Amount: 20, Time: baseTime.Add(3 * time.Minute)},
+			},
+			expectedLimit: 70,
+			expectedAccumulator: Accumulator{
+				Duration:           2 * time.Minute,
+				CurrentPeriodUsed:  1,
+				CurrentPeriodSpend: 20,
+				PeriodEndsDate:     baseTime.Add(5 * time.Minute),
+			},
+		},
+		{
+			name: "recusas e regras acionadas não mudam a conta",
+			events: []Event{
+				created,
+				RuleTriggered{Rule: "max transactions in 2 minutes", Time: baseTime},
+				TransactionDeclined{Amount: 10, Time: baseTime},
+			},
+			expectedLimit:       100,
+			expectedAccumulator: Accumulator{Duration: 2 * time.Minute},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var account Account
+
+			for _, event := range tt.events {
+				account.Apply(event)
+			}
+
+			assert.Equal(t, tt.expectedLimit, account.Ledger.AvailableLimit)
+			assert.Equal(t, tt.expectedAccumulator, *account.SpendingControl.Rules[0].Accumulator)
+		})
+	}
+
+	assert.Equal(t, Accumulator{Duration: 2 * time.Minute}, *created.Rules[0].Accumulator, "the event is left untouched")
+}
@@ -14,6 +14,9 @@ func (ac *Accumulator) AddSpend(amount int64) {
 	ac.CurrentPeriodUsed++
 }
 
+// BuildAccumulator move the accumulator to its period at currentTime, a new period starts
+// once the current one ended. A time before the current period, from a late transaction,
+// counts toward the current period instead of reopening one that already ended
 func BuildAccumulator(
 	currentTime time.Time,
 	duration time.Duration,
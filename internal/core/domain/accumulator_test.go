@@ -66,6 +66,22 @@ func TestBuildAccumulator(t *testing.T) {
 				PeriodEndsDate:     time.Date(2021, 10, 26, 10, 4, 30, 0, time.Local),
 			},
 		},
+		{
+			name: "buildando um acumulador para uma transação anterior ao período atual",
+			args: args{
+				currentTime:        time.Date(2021, 10, 26, 10, 1, 30, 0, time.Local),
+				duration:           2 * time.Minute,
+				currentPeriodUsed:  3,
+				currentPeriodSpend: 500,
+				periodEndsDate:     time.Date(2021, 10, 26, 10, 4, 0, 0, time.Local),
+			},
+			expectedResult: Accumulator{
+				Duration:           2 * time.Minute,
+				CurrentPeriodUsed:  3,
+				CurrentPeriodSpend: 500,
+				PeriodEndsDate:     time.Date(2021, 10, 26, 10, 4, 0, 0, time.Local),
+			},
+		},
 	}
 
 	for _, tt := range testCases {
@@ -0,0 +1,59 @@
+package domain
+
+// Check names for the verifications that are not spending control rules, rules are
+// reported under their own name
+const (
+	AmountCheck             = "amount"
+	MerchantCheck           = "merchant"
+	TimeCheck               = "time"
+	AccountCheck            = "account"
+	ActiveCardCheck         = "active-card"
+	OrderCheck              = "order"
+	LedgerCheck             = "ledger"
+	DoubledTransactionCheck = "doubled-transaction"
+)
+
+// builtInChecks are the check names rules cannot take, checks are told apart from rules by name
+var builtInChecks = map[string]bool{
+	AmountCheck:             true,
+	MerchantCheck:           true,
+	TimeCheck:               true,
+	AccountCheck:            true,
+	ActiveCardCheck:         true,
+	OrderCheck:              true,
+	LedgerCheck:             true,
+	DoubledTransactionCheck: true,
+}
+
+// IsBuiltInCheck tells if name is the name of a check that is not a spending control rule
+func IsBuiltInCheck(name string) bool {
+	return builtInChecks[name]
+}
+
+// Check is the outcome of one verification made while authorizing a transaction,
+// Details carries the numbers it was based on
+type Check struct {
+	Name      string
+	Passed    bool
+	Violation string
+	Details   map[string]interface{}
+}
+
+// NewCheck create a new Check instance, it fails when violation is not empty
+func NewCheck(name string, violation string, details map[string]interface{}) Check {
+	return Check{
+		Name:      name,
+		Passed:    violation == "",
+		Violation: violation,
+		Details:   details,
+	}
+}
+
+// Decision is the outcome of an authorization: the account after it, the violations
+// found, every check that ran, in order, and the ID the authorization was recorded under
+type Decision struct {
+	AuthorizationID string
+	Account         *Account
+	Violations      Violations
+	Checks          []Check
+}
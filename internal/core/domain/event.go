@@ -0,0 +1,67 @@
+package domain
+
+import "time"
+
+// Event types
+const (
+	AccountCreatedEvent      = "AccountCreated"
+	TransactionApprovedEvent = "TransactionApproved"
+	TransactionDeclinedEvent = "TransactionDeclined"
+	RuleTriggeredEvent       = "RuleTriggered"
+)
+
+// Event is something that happened to an account, published once the change is stored
+type Event interface {
+	EventType() string
+	OccurredAt() time.Time
+}
+
+// AccountCreated carries the account as it was initialized, rules included
+type AccountCreated struct {
+	AccountID int64
+	Ledger    Ledger
+	Rules     []Rule
+	Time      time.Time
+}
+
+func (e AccountCreated) EventType() string     { return AccountCreatedEvent }
+func (e AccountCreated) OccurredAt() time.Time { return e.Time }
+
+// TransactionApproved carries the available limit left after the transaction
+type TransactionApproved struct {
+	AccountID       int64
+	AuthorizationID string
+	Merchant        string
+	Amount          int64
+	AvailableLimit  int64
+	Time            time.Time
+}
+
+func (e TransactionApproved) EventType() string     { return TransactionApprovedEvent }
+func (e TransactionApproved) OccurredAt() time.Time { return e.Time }
+
+// TransactionDeclined carries every violation that declined the transaction
+type TransactionDeclined struct {
+	AccountID       int64
+	AuthorizationID string
+	Merchant        string
+	Amount          int64
+	AvailableLimit  int64
+	Violations      Violations
+	Time            time.Time
+}
+
+func (e TransactionDeclined) EventType() string     { return TransactionDeclinedEvent }
+func (e TransactionDeclined) OccurredAt() time.Time { return e.Time }
+
+// RuleTriggered tells a spending control rule declined a transaction
+type RuleTriggered struct {
+	AccountID       int64
+	AuthorizationID string
+	Rule            string
+	Violation       string
+	Time            time.Time
+}
+
+func (e RuleTriggered) EventType() string     { return RuleTriggeredEvent }
+func (e RuleTriggered) OccurredAt() time.Time { return e.Time }
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord keeps the response given to the first operation sent with a key,
+// Fingerprint identifies the payload of that operation
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	Response    []byte
+	CreatedAt   time.Time
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// OrderingPolicy decides what happens to a transaction timestamped before the latest one
+// already authorized
+type OrderingPolicy struct {
+	// Lateness is how far behind the latest transaction one may be and still be evaluated,
+	// zero rejects every transaction out of order
+	Lateness time.Duration
+	// AnyLateness evaluates every transaction as it arrives however far behind it is, Lateness
+	// aside, so no transaction is out of order
+	AnyLateness bool
+}
+
+// Accepts tells if a transaction at current can be evaluated once latest was authorized.
+// Transactions at the same time as the latest, or after it, are always in order
+func (p OrderingPolicy) Accepts(latest, current time.Time) bool {
+	return p.AnyLateness || !current.Before(latest.Add(-p.Lateness))
+}
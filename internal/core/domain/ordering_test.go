@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderingPolicy_Accepts(t *testing.T) {
+	latest := time.Date(2021, 10, 26, 10, 30, 0, 0, time.Local)
+
+	testCases := []struct {
+		name     string
+		policy   OrderingPolicy
+		current  time.Time
+		expected bool
+	}{
+		{
+			name:     "transação posterior à última",
+			policy:   OrderingPolicy{},
+			current:  latest.Add(time.Second),
+			expected: true,
+		},
+		{
+			name:     "transação no mesmo instante da última",
+			policy:   OrderingPolicy{},
+			current:  latest,
+			expected: true,
+		},
+		{
+			name:     "transação atrasada sem tolerância",
+			policy:   OrderingPolicy{},
+			current:  latest.Add(-time.Second),
+			expected: false,
+		},
+		{
+			name:     "transação atrasada dentro da tolerância",
+			policy:   OrderingPolicy{Lateness: time.Minute},
+			current:  latest.Add(-time.Minute),
+			expected: true,
+		},
+		{
+			name:     "transação atrasada além da tolerância",
+			policy:   OrderingPolicy{Lateness: time.Minute},
+			current:  latest.Add(-time.Minute - time.Second),
+			expected: false,
+		},
+		{
+			name:     "transação atrasada aceita em qualquer atraso",
+			policy:   OrderingPolicy{AnyLateness: true},
+			current:  latest.Add(-24 * time.Hour),
+			expected: true,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.policy.Accepts(latest, tt.current))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// OutboxEntry is an event stored with the change it came from, waiting to be delivered,
+// along with the bookkeeping of the delivery attempts
+type OutboxEntry struct {
+	ID            int64
+	Event         Event
+	CreatedAt     time.Time
+	Attempts      int
+	LastAttemptAt time.Time
+	LastError     string
+	DeliveredAt   time.Time
+}
+
+// Delivered tells whether the event reached the publisher
+func (e OutboxEntry) Delivered() bool {
+	return !e.DeliveredAt.IsZero()
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// DoubledTransactionWindow is how far back a transaction is compared against previous authorizations
+const DoubledTransactionWindow = 2 * time.Minute
+
+// DefaultRetention is how long authorizations are kept for lookups, listings, refunds and
+// disputes when no other retention is configured
+const DefaultRetention = 90 * 24 * time.Hour
+
+// RetentionPolicy decides how long authorizations are kept in the history
+type RetentionPolicy struct {
+	// MaxAge is how long an authorization is kept, zero keeps the whole history
+	MaxAge time.Duration
+}
+
+// PruneBefore returns the instant before which authorizations can be dropped, never cutting
+// into the window still used by the doubled-transaction check of a transaction as late as
+// the ordering policy accepts
+func (p RetentionPolicy) PruneBefore(currentTime time.Time, ordering OrderingPolicy) (time.Time, bool) {
+	if p.MaxAge <= 0 {
+		return time.Time{}, false
+	}
+
+	maxAge := p.MaxAge
+	if minAge := DoubledTransactionWindow + ordering.Lateness; maxAge < minAge {
+		maxAge = minAge
+	}
+
+	return currentTime.Add(-maxAge), true
+}
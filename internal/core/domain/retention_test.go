@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionPolicy_PruneBefore(t *testing.T) {
+	currentTime := time.Date(2021, 10, 26, 10, 30, 0, 0, time.Local)
+
+	testCases := []struct {
+		name          string
+		policy        RetentionPolicy
+		ordering      OrderingPolicy
+		expectedPrune bool
+		expectedTime  time.Time
+	}{
+		{
+			name:          "política sem idade máxima mantém todo o histórico",
+			policy:        RetentionPolicy{},
+			expectedPrune: false,
+		},
+		{
+			name:          "política com idade máxima maior que a janela de duplicidade",
+			policy:        RetentionPolicy{MaxAge: 10 * time.Minute},
+			expectedPrune: true,
+			expectedTime:  time.Date(2021, 10, 26, 10, 20, 0, 0, time.Local),
+		},
+		{
+			name:          "política com idade máxima menor que a janela de duplicidade",
+			policy:        RetentionPolicy{MaxAge: 30 * time.Second},
+			expectedPrune: true,
+			expectedTime:  time.Date(2021, 10, 26, 10, 28, 0, 0, time.Local),
+		},
+		{
+			name:          "política com tolerância a atrasos mantém a janela do mais atrasado",
+			policy:        RetentionPolicy{MaxAge: 30 * time.Second},
+			ordering:      OrderingPolicy{Lateness: time.Minute},
+			expectedPrune: true,
+			expectedTime:  time.Date(2021, 10, 26, 10, 27, 0, 0, time.Local),
+		},
+		{
+			name:          "política com idade máxima maior que a janela e a tolerância",
+			policy:        RetentionPolicy{MaxAge: 10 * time.Minute},
+			ordering:      OrderingPolicy{Lateness: time.Minute},
+			expectedPrune: true,
+			expectedTime:  time.Date(2021, 10, 26, 10, 20, 0, 0, time.Local),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			before, prune := tt.policy.PruneBefore(currentTime, tt.ordering)
+
+			assert.Equal(t, tt.expectedPrune, prune)
+			assert.Equal(t, tt.expectedTime, before)
+		})
+	}
+}
@@ -1,5 +1,10 @@
 package domain
 
+import "time"
+
+// UsageLimitRule is the rule type that limits how many transactions are approved in a period
+const UsageLimitRule = "usage-limit"
+
 type Rule struct {
 	Name          string
 	Type          string
@@ -7,3 +12,29 @@ type Rule struct {
 	Accumulator   *Accumulator
 	RuleViolation string
 }
+
+// Remaining tells how many more transactions a usage-limit rule allows in the current period
+func (r Rule) Remaining() int64 {
+	if r.Accumulator == nil || r.UsageLimit <= r.Accumulator.CurrentPeriodUsed {
+		return 0
+	}
+
+	return r.UsageLimit - r.Accumulator.CurrentPeriodUsed
+}
+
+// DefaultRules return the spending control rules accounts are created with when no other
+// rules are configured
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:       "max transactions in 2 minutes",
+			Type:       UsageLimitRule,
+			UsageLimit: 3,
+			Accumulator: &Accumulator{
+				Duration:          2 * time.Minute,
+				CurrentPeriodUsed: 0,
+			},
+			RuleViolation: "high-frequency-small-interval",
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Remaining(t *testing.T) {
+	testCases := []struct {
+		name     string
+		rule     Rule
+		expected int64
+	}{
+		{
+			name:     "regra sem uso no período",
+			rule:     Rule{UsageLimit: 3, Accumulator: &Accumulator{Duration: 2 * time.Minute}},
+			expected: 3,
+		},
+		{
+			name:     "regra parcialmente usada",
+			rule:     Rule{UsageLimit: 3, Accumulator: &Accumulator{CurrentPeriodUsed: 2}},
+			expected: 1,
+		},
+		{
+			name:     "regra esgotada",
+			rule:     Rule{UsageLimit: 3, Accumulator: &Accumulator{CurrentPeriodUsed: 4}},
+			expected: 0,
+		},
+		{
+			name:     "regra sem acumulador",
+			rule:     Rule{UsageLimit: 3},
+			expected: 0,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.rule.Remaining())
+		})
+	}
+}
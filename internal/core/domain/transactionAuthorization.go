@@ -2,9 +2,18 @@ package domain
 
 import "time"
 
+// TransactionAuthorization records an authorization, declined ones keep the violations
+// that caused the decline
 type TransactionAuthorization struct {
+	ID             string
 	Merchant       string
 	Amount         int64
 	AvailableLimit int64
 	Time           time.Time
+	Violations     Violations
+}
+
+// Approved tells if the authorization was granted
+func (ta TransactionAuthorization) Approved() bool {
+	return len(ta.Violations) == 0
 }
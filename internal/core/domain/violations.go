@@ -6,6 +6,16 @@ var (
 	InsufficientLimitViolation         = "insufficient-limit"
 	AccountAlreadyInitializedViolation = "account-already-initialized"
 	DoubledTransactionViolation        = "doubled-transaction"
+	SystemErrorViolation               = "system-error"
+	InvalidOperationViolation          = "invalid-operation"
+	InvalidAmountViolation             = "invalid-amount"
+	InvalidMerchantViolation           = "invalid-merchant"
+	InvalidTimeViolation               = "invalid-time"
+	InvalidLimitViolation              = "invalid-limit"
+	AuthorizationNotFoundViolation     = "authorization-not-found"
+	IdempotencyKeyReusedViolation      = "idempotency-key-reused"
+	InvalidQueryViolation              = "invalid-query"
+	TransactionOutOfOrderViolation     = "transaction-out-of-order"
 )
 
 type Violations []string
@@ -5,8 +5,10 @@ import (
 	"time"
 )
 
+// AccountRepository stores the account, Create and Update store the events of the change
+// in the outbox within the same write, so either both are kept or none
 type AccountRepository interface {
-	Create(account domain.Account) error
+	Create(account domain.Account, events ...domain.Event) error
 	Retrieve(currentTime time.Time) (*domain.Account, error)
-	Update(account domain.Account) error
+	Update(account domain.Account, events ...domain.Event) error
 }
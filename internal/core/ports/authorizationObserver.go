@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// AuthorizationObserver is told about every authorization once it is decided, err is set when
+// it could not be and latency is how long the authorization took
+type AuthorizationObserver interface {
+	ObserveAuthorization(transaction domain.Transaction, decision domain.Decision, err error, latency time.Duration)
+}
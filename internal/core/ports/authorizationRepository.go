@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// AuthorizationQuery filters and pages the authorization history, zero values are unbounded
+type AuthorizationQuery struct {
+	From     time.Time
+	To       time.Time
+	Merchant string
+	Offset   int
+	Limit    int
+}
+
+// AuthorizationPage is a time-ordered page of the authorization history, Total counts every
+// match and Offset and Limit echo the query that produced the page
+type AuthorizationPage struct {
+	Authorizations []domain.TransactionAuthorization
+	Total          int
+	Offset         int
+	Limit          int
+}
+
+// AuthorizationRepository keeps approved and declined authorizations, FindNearest and
+// LatestApproved only consider the approved ones
+type AuthorizationRepository interface {
+	Save(authorization domain.TransactionAuthorization) error
+	Find(id string) (*domain.TransactionAuthorization, error)
+	List(query AuthorizationQuery) (AuthorizationPage, error)
+	FindNearest(merchant string, amount int64, at time.Time) (*domain.TransactionAuthorization, error)
+	LatestApproved() (*domain.TransactionAuthorization, error)
+	Prune(before time.Time) (int, error)
+}
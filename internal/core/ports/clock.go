@@ -0,0 +1,9 @@
+package ports
+
+import "time"
+
+// Clock tells the current time to the paths that do not get it from the operation itself,
+// such as account creation, idempotency windows and outbox retention
+type Clock interface {
+	Now() time.Time
+}
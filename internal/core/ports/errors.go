@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrAccountNotFound is returned when there is no account stored
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrAccountAlreadyExists is returned when creating an account that is already stored
+	ErrAccountAlreadyExists = errors.New("account already exists")
+	// ErrAuthorizationNotFound is returned when no authorization matches a lookup
+	ErrAuthorizationNotFound = errors.New("authorization not found")
+	// ErrIdempotencyKeyNotFound is returned when no response was recorded for a key
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	// ErrOutboxEntryNotFound is returned when marking an entry that is not in the outbox
+	ErrOutboxEntryNotFound = errors.New("outbox entry not found")
+	// ErrResponseNotRecorded is returned along with the response of an operation that ran but
+	// whose response could not be recorded under its idempotency key
+	ErrResponseNotRecorded = errors.New("response not recorded")
+)
+
+// StorageError reports a failure of the storage behind a repository
+type StorageError struct {
+	Op  string
+	Err error
+}
+
+func (e *StorageError) Error() string {
+	return fmt.Sprintf("storage error on %s: %v", e.Op, e.Err)
+}
+
+func (e *StorageError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,8 @@
+package ports
+
+import "github.com/authorizer/internal/core/domain"
+
+// EventPublisher delivers domain events to whoever reacts to them
+type EventPublisher interface {
+	Publish(events ...domain.Event) error
+}
@@ -0,0 +1,6 @@
+package ports
+
+// IDGenerator creates unique identifiers that sort in the order they were created
+type IDGenerator interface {
+	NewID() string
+}
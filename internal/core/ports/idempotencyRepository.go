@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+type IdempotencyRepository interface {
+	Save(record domain.IdempotencyRecord) error
+	Find(key string) (*domain.IdempotencyRecord, error)
+	Prune(before time.Time) (int, error)
+}
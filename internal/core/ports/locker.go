@@ -0,0 +1,6 @@
+package ports
+
+// Locker serializes operations over the same account
+type Locker interface {
+	Lock(accountID int64) (unlock func())
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// Outbox holds the events waiting to be delivered, in the order they were stored
+type Outbox interface {
+	Append(events ...domain.Event) error
+	Pending(limit int) ([]domain.OutboxEntry, error)
+	MarkDelivered(id int64, at time.Time) error
+	MarkFailed(id int64, at time.Time, cause error) error
+	Prune(before time.Time) (int, error)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"time"
 
 	"github.com/authorizer/internal/core/domain"
@@ -8,17 +9,39 @@ import (
 )
 
 type Account struct {
-	repo ports.AccountRepository
+	repo   ports.AccountRepository
+	locker ports.Locker
+	clock  ports.Clock
+	rules  []domain.Rule
 }
 
-func NewAccount(r ports.AccountRepository) Account {
-	return Account{repo: r}
+func NewAccount(r ports.AccountRepository, l ports.Locker, c ports.Clock) Account {
+	return NewAccountWithRules(r, l, c, domain.DefaultRules())
 }
-func (a Account) InitAccount(activeCard bool, maxLimit int64) (*domain.Account, []string) {
-	existentAccount, _ := a.repo.Retrieve(time.Now())
+
+// NewAccountWithRules create a new Account instance whose accounts are created with the rules
+func NewAccountWithRules(r ports.AccountRepository, l ports.Locker, c ports.Clock, rules []domain.Rule) Account {
+	return Account{repo: r, locker: l, clock: c, rules: copyRules(rules)}
+}
+
+func (a Account) InitAccount(activeCard bool, maxLimit int64) (*domain.Account, []string, error) {
+	if maxLimit < 0 {
+		return nil, []string{domain.InvalidLimitViolation}, nil
+	}
+
+	unlock := a.locker.Lock(domain.DefaultAccountID)
+	defer unlock()
+
+	now := a.clock.Now()
+
+	existentAccount, err := a.repo.Retrieve(now)
+
+	if err != nil && !errors.Is(err, ports.ErrAccountNotFound) {
+		return nil, nil, err
+	}
 
 	if existentAccount != nil {
-		return existentAccount, []string{domain.AccountAlreadyInitializedViolation}
+		return existentAccount, []string{domain.AccountAlreadyInitializedViolation}, nil
 	}
 
 	newAccount := domain.Account{
@@ -27,24 +50,57 @@ func (a Account) InitAccount(activeCard bool, maxLimit int64) (*domain.Account,
 			MaxLimit:       maxLimit,
 			AvailableLimit: maxLimit,
 		},
-		SpendingControl: domain.SpendingControl{
-			Rules: []domain.Rule{
-				{
-					Name:       "max transactions in 2 minutes",
-					Type:       "usage-limit",
-					UsageLimit: 3,
-					Accumulator: &domain.Accumulator{
-						Duration:          2 * time.Minute,
-						CurrentPeriodUsed: 0,
-					},
-					RuleViolation: "high-frequency-small-interval",
-				},
-			},
-		},
-		Authorizations: []domain.TransactionAuthorization{},
+		SpendingControl: domain.SpendingControl{Rules: copyRules(a.rules)},
+	}
+
+	created := domain.AccountCreated{
+		AccountID: domain.DefaultAccountID,
+		Ledger:    newAccount.Ledger,
+		Rules:     copyRules(newAccount.SpendingControl.Rules),
+		Time:      now,
+	}
+
+	if err := a.repo.Create(newAccount, created); err != nil {
+		return nil, nil, err
 	}
 
-	_ = a.repo.Create(newAccount)
+	return &newAccount, []string{}, nil
+}
+
+// GetAccount return the stored account as it stands on the clock, or the account-not-initialized
+// violation when there is none
+func (a Account) GetAccount() (*domain.Account, []string, error) {
+	return a.GetAccountAt(a.clock.Now())
+}
+
+// GetAccountAt return the stored account with the rule accumulators as they stand at the
+// given instant, or the account-not-initialized violation when there is none
+func (a Account) GetAccountAt(at time.Time) (*domain.Account, []string, error) {
+	account, err := a.repo.Retrieve(at)
+
+	if errors.Is(err, ports.ErrAccountNotFound) {
+		return nil, []string{domain.AccountNotInitializedViolation}, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return account, []string{}, nil
+}
+
+// copyRules copy the rules with their accumulators, so accounts and events do not share state
+func copyRules(rules []domain.Rule) []domain.Rule {
+	copied := make([]domain.Rule, 0, len(rules))
+
+	for _, rule := range rules {
+		if rule.Accumulator != nil {
+			accumulator := *rule.Accumulator
+			rule.Accumulator = &accumulator
+		}
+
+		copied = append(copied, rule)
+	}
 
-	return &newAccount, []string{}
+	return copied
 }
@@ -1,7 +1,12 @@
 package service
 
 import (
+	"errors"
+
 	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/lock"
 	"github.com/authorizer/internal/driven/repository"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -33,22 +38,102 @@ func TestAccount_InitAccount_Without_Violations(t *testing.T) {
 				},
 			},
 		},
-		Authorizations: []domain.TransactionAuthorization{},
 	}
 
 	accountRepoMock := repository.NewMockAccountRepository(ctrl)
 
-	accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(nil, nil)
-	accountRepoMock.EXPECT().Create(expectedAccount).Return(nil)
+	accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(nil, ports.ErrAccountNotFound)
+	accountRepoMock.EXPECT().Create(expectedAccount, gomock.AssignableToTypeOf(domain.AccountCreated{})).Return(nil)
 
-	as := NewAccount(accountRepoMock)
+	as := NewAccount(accountRepoMock, lock.NewInMemoryLocker(), clock.NewSystemClock())
 
-	account, _ := as.InitAccount(true, 200)
+	account, _, err := as.InitAccount(true, 200)
 
+	assert.NoError(t, err)
 	assert.Equal(t, account.Ledger.AvailableLimit, int64(200))
 	assert.Equal(t, account.Ledger.ActiveCard, true)
 }
 
+func TestAccount_InitAccount_Stores_AccountCreated(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+
+	as := NewAccount(accountRepo, lock.NewInMemoryLocker(), clock.NewSystemClock())
+
+	_, _, _ = as.InitAccount(true, 200)
+	_, violations, _ := as.InitAccount(true, 200)
+
+	assert.Equal(t, []string{domain.AccountAlreadyInitializedViolation}, violations)
+
+	pending, _ := accountRepo.Outbox().Pending(0)
+	assert.Len(t, pending, 1, "only the account actually created is stored")
+
+	created := pending[0].Event.(domain.AccountCreated)
+	assert.Equal(t, domain.DefaultAccountID, created.AccountID)
+	assert.Equal(t, domain.Ledger{ActiveCard: true, MaxLimit: 200, AvailableLimit: 200}, created.Ledger)
+	assert.Len(t, created.Rules, 1)
+	assert.Equal(t, 2*time.Minute, created.Rules[0].Accumulator.Duration)
+}
+
+func TestAccount_Uses_The_Clock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(baseTime)
+
+	accountRepoMock := repository.NewMockAccountRepository(ctrl)
+
+	gomock.InOrder(
+		accountRepoMock.EXPECT().Retrieve(baseTime).Return(nil, ports.ErrAccountNotFound),
+		accountRepoMock.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(account domain.Account, events ...domain.Event) error {
+			assert.Equal(t, baseTime, events[0].(domain.AccountCreated).Time)
+			return nil
+		}),
+		accountRepoMock.EXPECT().Retrieve(baseTime.Add(time.Minute)).Return(&domain.Account{}, nil),
+	)
+
+	as := NewAccount(accountRepoMock, lock.NewInMemoryLocker(), fakeClock)
+
+	_, _, err := as.InitAccount(true, 200)
+	assert.NoError(t, err)
+
+	fakeClock.Advance(time.Minute)
+
+	_, _, err = as.GetAccount()
+	assert.NoError(t, err)
+}
+
+func TestAccount_InitAccount_With_Configured_Rules(t *testing.T) {
+	rules := []domain.Rule{
+		{
+			Name:          "max transactions in 1 minute",
+			Type:          domain.UsageLimitRule,
+			UsageLimit:    1,
+			Accumulator:   &domain.Accumulator{Duration: time.Minute},
+			RuleViolation: "high-frequency-small-interval",
+		},
+	}
+
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+
+	as := NewAccountWithRules(accountRepo, lock.NewInMemoryLocker(), clock.NewSystemClock(), rules)
+
+	rules[0].Accumulator.CurrentPeriodUsed = 5
+
+	account, _, err := as.InitAccount(true, 100)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Rule{
+		{
+			Name:          "max transactions in 1 minute",
+			Type:          domain.UsageLimitRule,
+			UsageLimit:    1,
+			Accumulator:   &domain.Accumulator{Duration: time.Minute},
+			RuleViolation: "high-frequency-small-interval",
+		},
+	}, account.SpendingControl.Rules, "the rules are copied when the service is created")
+}
+
 func TestAccount_InitAccount_With_Violations(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -73,16 +158,127 @@ func TestAccount_InitAccount_With_Violations(t *testing.T) {
 				},
 			},
 		},
-		Authorizations: []domain.TransactionAuthorization{},
 	}
 
 	accountRepoMock := repository.NewMockAccountRepository(ctrl)
 
 	accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(&mockAccount, nil)
 
-	as := NewAccount(accountRepoMock)
+	as := NewAccount(accountRepoMock, lock.NewInMemoryLocker(), clock.NewSystemClock())
 
-	_, violations := as.InitAccount(true, 200)
+	_, violations, err := as.InitAccount(true, 200)
 
+	assert.NoError(t, err)
 	assert.Equal(t, violations, []string{"account-already-initialized"})
 }
+
+func TestAccount_InitAccount_With_Invalid_Limit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepoMock := repository.NewMockAccountRepository(ctrl)
+
+	as := NewAccount(accountRepoMock, lock.NewInMemoryLocker(), clock.NewSystemClock())
+
+	account, violations, err := as.InitAccount(true, -100)
+
+	assert.NoError(t, err)
+	assert.Nil(t, account)
+	assert.Equal(t, []string{domain.InvalidLimitViolation}, violations)
+}
+
+func TestAccount_InitAccount_With_Storage_Errors(t *testing.T) {
+	storageErr := &ports.StorageError{Op: "retrieve", Err: errors.New("connection refused")}
+
+	testCases := []struct {
+		name      string
+		setupMock func(m *repository.MockAccountRepository)
+	}{
+		{
+			name: "Falha ao buscar a conta",
+			setupMock: func(m *repository.MockAccountRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(nil, storageErr)
+			},
+		},
+		{
+			name: "Falha ao criar a conta",
+			setupMock: func(m *repository.MockAccountRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(nil, ports.ErrAccountNotFound)
+				m.EXPECT().Create(gomock.Any(), gomock.Any()).Return(storageErr)
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepoMock := repository.NewMockAccountRepository(ctrl)
+			tt.setupMock(accountRepoMock)
+
+			as := NewAccount(accountRepoMock, lock.NewInMemoryLocker(), clock.NewSystemClock())
+
+			account, violations, err := as.InitAccount(true, 200)
+
+			assert.Nil(t, account)
+			assert.Empty(t, violations)
+			assert.ErrorIs(t, err, storageErr)
+		})
+	}
+}
+
+func TestAccount_GetAccount(t *testing.T) {
+	storageErr := &ports.StorageError{Op: "retrieve", Err: errors.New("connection refused")}
+	storedAccount := &domain.Account{
+		Ledger: domain.Ledger{
+			ActiveCard:     true,
+			MaxLimit:       200,
+			AvailableLimit: 150,
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		mockAccount        *domain.Account
+		mockErr            error
+		expectedAccount    *domain.Account
+		expectedViolations []string
+		expectedErr        error
+	}{
+		{
+			name:               "Consultando uma conta existente",
+			mockAccount:        storedAccount,
+			expectedAccount:    storedAccount,
+			expectedViolations: []string{},
+		},
+		{
+			name:               "Consultando uma conta que não foi inicializada",
+			mockErr:            ports.ErrAccountNotFound,
+			expectedViolations: []string{"account-not-initialized"},
+		},
+		{
+			name:        "Falha ao buscar a conta",
+			mockErr:     storageErr,
+			expectedErr: storageErr,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepoMock := repository.NewMockAccountRepository(ctrl)
+			accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(tt.mockAccount, tt.mockErr)
+
+			as := NewAccount(accountRepoMock, lock.NewInMemoryLocker(), clock.NewSystemClock())
+
+			account, violations, err := as.GetAccount()
+
+			assert.Equal(t, tt.expectedAccount, account)
+			assert.Equal(t, tt.expectedViolations, violations)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
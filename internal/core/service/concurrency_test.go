@@ -0,0 +1,233 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/database"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrentStorage is an account storage with the outbox its events go to and the history
+// the authorizations are kept in
+type concurrentStorage struct {
+	accounts       ports.AccountRepository
+	outbox         ports.Outbox
+	authorizations ports.AuthorizationRepository
+}
+
+func newDatabaseStorage() concurrentStorage {
+	db := database.NewInMemoryDB()
+	authorizationRepo := repository.NewAuthorizationRepository()
+
+	return concurrentStorage{
+		accounts:       repository.NewAccountRepository(db, authorizationRepo, clock.NewSystemClock()),
+		outbox:         repository.NewOutboxRepository(db, clock.NewSystemClock()),
+		authorizations: authorizationRepo,
+	}
+}
+
+func newMemoryStorage() concurrentStorage {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+
+	return concurrentStorage{accounts: accountRepo, outbox: accountRepo.Outbox(), authorizations: repository.NewAuthorizationRepository()}
+}
+
+func newEventSourcedStorage() concurrentStorage {
+	accountRepo := repository.NewEventSourcedAccountRepository(clock.NewSystemClock(), 10)
+
+	return concurrentStorage{accounts: accountRepo, outbox: accountRepo, authorizations: accountRepo.History()}
+}
+
+func TestTransaction_Authorize_Concurrently(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name      string
+		storage   func() concurrentStorage
+		workers   int
+		perWorker int
+		amount    int64
+	}{
+		{name: "transações concorrentes no banco de dados", storage: newDatabaseStorage, workers: 20, perWorker: 25, amount: 10},
+		{name: "transações concorrentes em memória", storage: newMemoryStorage, workers: 20, perWorker: 25, amount: 10},
+		{name: "transações concorrentes no armazenamento por eventos", storage: newEventSourcedStorage, workers: 10, perWorker: 10, amount: 10},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := tt.storage()
+			locker := lock.NewInMemoryLocker()
+			total := tt.workers * tt.perWorker
+			initialLimit := int64(total) * tt.amount
+
+			_, _, err := NewAccountWithRules(storage.accounts, locker, clock.NewSystemClock(), []domain.Rule{}).InitAccount(true, initialLimit)
+			assert.NoError(t, err)
+
+			ts := NewTransaction(storage.accounts, storage.authorizations, locker, identifier.NewSequentialGenerator(), storage.outbox, domain.RetentionPolicy{}).
+				// workers interleave, so transactions arrive out of order within the whole span of times
+				WithOrdering(domain.OrderingPolicy{Lateness: time.Duration(total) * time.Second})
+
+			var wg sync.WaitGroup
+
+			for w := 0; w < tt.workers; w++ {
+				wg.Add(1)
+
+				go func(w int) {
+					defer wg.Done()
+
+					for i := 0; i < tt.perWorker; i++ {
+						decision, err := ts.Authorize(domain.Transaction{
+							Merchant: fmt.Sprintf("merchant-%d-%d", w, i),
+							Amount:   tt.amount,
+							Time:     baseTime.Add(time.Duration(w*tt.perWorker+i) * time.Second),
+						})
+
+						assert.NoError(t, err)
+						assert.Empty(t, decision.Violations)
+					}
+				}(w)
+			}
+
+			wg.Wait()
+
+			account, _ := storage.accounts.Retrieve(baseTime)
+			page, _ := storage.authorizations.List(ports.AuthorizationQuery{})
+
+			assert.Equal(t, int64(0), account.Ledger.AvailableLimit)
+			assert.Equal(t, total, page.Total)
+		})
+	}
+}
+
+func TestAccount_InitAccount_Concurrently(t *testing.T) {
+	testCases := []struct {
+		name            string
+		storage         func() concurrentStorage
+		workers         int
+		expectedCreated int
+	}{
+		{name: "criações concorrentes no banco de dados", storage: newDatabaseStorage, workers: 50, expectedCreated: 1},
+		{name: "criações concorrentes em memória", storage: newMemoryStorage, workers: 50, expectedCreated: 1},
+		{name: "criações concorrentes no armazenamento por eventos", storage: newEventSourcedStorage, workers: 50, expectedCreated: 1},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			as := NewAccount(tt.storage().accounts, lock.NewInMemoryLocker(), clock.NewSystemClock())
+
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				created int
+			)
+
+			for w := 0; w < tt.workers; w++ {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					_, violations, err := as.InitAccount(true, 100)
+
+					if err == nil && len(violations) == 0 {
+						mu.Lock()
+						created++
+						mu.Unlock()
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			assert.Equal(t, tt.expectedCreated, created)
+		})
+	}
+}
+
+// stalledHistory holds the first authorization saved to it for a while, so whoever migrates the
+// history first is still at it when the next operation starts. migrating is closed once it is
+func stalledHistory() *slowHistory {
+	return &slowHistory{AuthorizationRepository: repository.NewAuthorizationRepository(), migrating: make(chan struct{})}
+}
+
+type slowHistory struct {
+	*repository.AuthorizationRepository
+
+	stalled   int32
+	migrating chan struct{}
+}
+
+func (h *slowHistory) Save(authorization domain.TransactionAuthorization) error {
+	if atomic.CompareAndSwapInt32(&h.stalled, 0, 1) {
+		close(h.migrating)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	return h.AuthorizationRepository.Save(authorization)
+}
+
+func TestAccount_GetAccount_Upgrades_Concurrently(t *testing.T) {
+	const accountV1 = `{"ledger":{"active":true,"max_limit":1000,"available_limit":1000},"spending_control":{"rules":[]},` +
+		`"transactions":[{"merchant":"Merchant1","amount":50,"available_limit":1000,"time":"2021-10-10T10:00:00Z"}]}`
+
+	testCases := []struct {
+		name                   string
+		authorize              bool
+		expectedAvailableLimit int64
+		expectedAuthorizations int
+	}{
+		{name: "autorização durante a leitura da conta na versão 1", authorize: true, expectedAvailableLimit: 990, expectedAuthorizations: 2},
+		{name: "leitura durante a leitura da conta na versão 1", authorize: false, expectedAvailableLimit: 1000, expectedAuthorizations: 1},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewInMemoryDB()
+			_ = db.Insert("accounts", domain.DefaultAccountID, json.RawMessage(accountV1))
+
+			history := stalledHistory()
+			accountRepo := repository.NewAccountRepository(db, history, clock.NewSystemClock())
+			locker := lock.NewInMemoryLocker()
+
+			as := NewAccount(accountRepo, locker, clock.NewSystemClock())
+			ts := NewTransaction(accountRepo, history, locker, identifier.NewSequentialGenerator(), repository.NewOutboxRepository(db, clock.NewSystemClock()), domain.RetentionPolicy{})
+
+			read := make(chan error, 1)
+
+			go func() {
+				_, _, err := as.GetAccount()
+				read <- err
+			}()
+
+			<-history.migrating
+
+			if tt.authorize {
+				decision, err := ts.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 10, Time: time.Date(2021, 10, 10, 11, 0, 0, 0, time.UTC)})
+				assert.NoError(t, err)
+				assert.Empty(t, decision.Violations)
+			} else {
+				_, _, err := as.GetAccount()
+				assert.NoError(t, err)
+			}
+
+			assert.NoError(t, <-read)
+
+			account, _ := accountRepo.Retrieve(time.Now())
+			page, _ := history.List(ports.AuthorizationQuery{})
+
+			assert.Equal(t, tt.expectedAvailableLimit, account.Ledger.AvailableLimit)
+			assert.Equal(t, tt.expectedAuthorizations, page.Total)
+		})
+	}
+}
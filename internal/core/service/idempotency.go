@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+// Idempotency service to answer operations retried with the same key with the response
+// recorded for the first one
+type Idempotency struct {
+	repo   ports.IdempotencyRepository
+	clock  ports.Clock
+	window time.Duration
+	locks  *keyLocks
+}
+
+// NewIdempotency create a new Idempotency instance, responses are kept for the window
+// measured on the clock
+func NewIdempotency(r ports.IdempotencyRepository, c ports.Clock, window time.Duration) Idempotency {
+	return Idempotency{repo: r, clock: c, window: window, locks: newKeyLocks()}
+}
+
+// Execute run the operation once per key and return its response. Within the window a key
+// sent again with the same fingerprint gets the recorded response without running the
+// operation, a key sent with another fingerprint gets the idempotency-key-reused violation.
+// Responses the operation does not want recorded, such as internal failures, are returned
+// but leave the key free for a retry. Storage failures before the operation leave it unrun;
+// once it ran its response is always returned, along with ports.ErrResponseNotRecorded when
+// it could not be recorded
+func (i Idempotency) Execute(
+	key string,
+	fingerprint string,
+	operation func() (response []byte, record bool),
+) ([]byte, []string, error) {
+	// operations under the same key are serialized so concurrent retries cannot both run,
+	// those under other keys go on
+	defer i.locks.lock(key)()
+
+	now := i.clock.Now()
+
+	existent, err := i.repo.Find(key)
+
+	if err != nil && !errors.Is(err, ports.ErrIdempotencyKeyNotFound) {
+		return nil, nil, err
+	}
+
+	if existent != nil && now.Sub(existent.CreatedAt) <= i.window {
+		if existent.Fingerprint != fingerprint {
+			return nil, []string{domain.IdempotencyKeyReusedViolation}, nil
+		}
+
+		return existent.Response, []string{}, nil
+	}
+
+	if _, err := i.repo.Prune(now.Add(-i.window)); err != nil {
+		return nil, nil, err
+	}
+
+	response, record := operation()
+
+	if !record {
+		return response, []string{}, nil
+	}
+
+	err = i.repo.Save(domain.IdempotencyRecord{
+		Key:         key,
+		Fingerprint: fingerprint,
+		Response:    response,
+		CreatedAt:   now,
+	})
+
+	if err != nil {
+		return response, []string{}, fmt.Errorf("%w: %v", ports.ErrResponseNotRecorded, err)
+	}
+
+	return response, []string{}, nil
+}
+
+// keyLocks keeps one mutex per idempotency key, released when nobody holds or waits for it
+type keyLocks struct {
+	mu      sync.Mutex
+	entries map[string]*keyLock
+}
+
+type keyLock struct {
+	mu      sync.Mutex
+	holders int
+}
+
+func newKeyLocks() *keyLocks {
+	return &keyLocks{entries: make(map[string]*keyLock)}
+}
+
+// lock blocks until the key is free and returns the function that releases it
+func (l *keyLocks) lock(key string) (unlock func()) {
+	l.mu.Lock()
+	e, exists := l.entries[key]
+	if !exists {
+		e = &keyLock{}
+		l.entries[key] = e
+	}
+	e.holders++
+	l.mu.Unlock()
+
+	e.mu.Lock()
+
+	return func() {
+		e.mu.Unlock()
+
+		l.mu.Lock()
+		e.holders--
+		if e.holders == 0 {
+			delete(l.entries, key)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency_Execute(t *testing.T) {
+	type call struct {
+		key         string
+		fingerprint string
+		elapsed     time.Duration
+		record      bool
+	}
+
+	testCases := []struct {
+		name               string
+		calls              []call
+		expectedResponses  []string
+		expectedViolations [][]string
+		expectedRuns       int
+	}{
+		{
+			name: "repetindo a operação com a mesma chave",
+			calls: []call{
+				{key: "a", fingerprint: "x", record: true},
+				{key: "a", fingerprint: "x", elapsed: time.Minute, record: true},
+			},
+			expectedResponses:  []string{"resposta 1", "resposta 1"},
+			expectedViolations: [][]string{{}, {}},
+			expectedRuns:       1,
+		},
+		{
+			name: "reutilizando a chave com outro conteúdo",
+			calls: []call{
+				{key: "a", fingerprint: "x", record: true},
+				{key: "a", fingerprint: "y", record: true},
+			},
+			expectedResponses:  []string{"resposta 1", ""},
+			expectedViolations: [][]string{{}, {domain.IdempotencyKeyReusedViolation}},
+			expectedRuns:       1,
+		},
+		{
+			name: "repetindo a operação depois da janela",
+			calls: []call{
+				{key: "a", fingerprint: "x", record: true},
+				{key: "a", fingerprint: "y", elapsed: 2 * time.Hour, record: true},
+			},
+			expectedResponses:  []string{"resposta 1", "resposta 2"},
+			expectedViolations: [][]string{{}, {}},
+			expectedRuns:       2,
+		},
+		{
+			name: "repetindo uma operação que não foi registrada",
+			calls: []call{
+				{key: "a", fingerprint: "x", record: false},
+				{key: "a", fingerprint: "x", record: true},
+				{key: "b", fingerprint: "x", record: true},
+			},
+			expectedResponses:  []string{"resposta 1", "resposta 2", "resposta 3"},
+			expectedViolations: [][]string{{}, {}, {}},
+			expectedRuns:       3,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+			fakeClock := clock.NewFakeClock(baseTime)
+			is := NewIdempotency(repository.NewIdempotencyRepository(), fakeClock, time.Hour)
+
+			runs := 0
+
+			for i, c := range tt.calls {
+				fakeClock.Set(baseTime.Add(c.elapsed))
+				record := c.record
+
+				response, violations, err := is.Execute(c.key, c.fingerprint, func() ([]byte, bool) {
+					runs++
+					return []byte(fmt.Sprintf("resposta %d", runs)), record
+				})
+
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResponses[i], string(response))
+				assert.Equal(t, tt.expectedViolations[i], violations)
+			}
+
+			assert.Equal(t, tt.expectedRuns, runs)
+		})
+	}
+}
+
+// failingIdempotencyRepository fails the operations it is told to
+type failingIdempotencyRepository struct {
+	*repository.IdempotencyRepository
+	saveErr  error
+	pruneErr error
+}
+
+func (r failingIdempotencyRepository) Save(record domain.IdempotencyRecord) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+
+	return r.IdempotencyRepository.Save(record)
+}
+
+func (r failingIdempotencyRepository) Prune(before time.Time) (int, error) {
+	if r.pruneErr != nil {
+		return 0, r.pruneErr
+	}
+
+	return r.IdempotencyRepository.Prune(before)
+}
+
+func TestIdempotency_Execute_Storage_Errors(t *testing.T) {
+	storageErr := errors.New("disk full")
+
+	testCases := []struct {
+		name             string
+		repo             failingIdempotencyRepository
+		expectedResponse string
+		expectedRuns     int
+		expectedErr      error
+	}{
+		{
+			name:             "falha ao limpar chaves antigas não executa a operação",
+			repo:             failingIdempotencyRepository{IdempotencyRepository: repository.NewIdempotencyRepository(), pruneErr: storageErr},
+			expectedResponse: "",
+			expectedRuns:     0,
+			expectedErr:      storageErr,
+		},
+		{
+			name:             "falha ao registrar a resposta devolve a resposta da operação",
+			repo:             failingIdempotencyRepository{IdempotencyRepository: repository.NewIdempotencyRepository(), saveErr: storageErr},
+			expectedResponse: "resposta 1",
+			expectedRuns:     1,
+			expectedErr:      ports.ErrResponseNotRecorded,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := NewIdempotency(tt.repo, clock.NewSystemClock(), time.Hour)
+
+			runs := 0
+
+			response, _, err := is.Execute("a", "x", func() ([]byte, bool) {
+				runs++
+				return []byte(fmt.Sprintf("resposta %d", runs)), true
+			})
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedResponse, string(response))
+			assert.Equal(t, tt.expectedRuns, runs)
+		})
+	}
+}
+
+func TestIdempotency_Execute_Concurrently(t *testing.T) {
+	testCases := []struct {
+		name         string
+		keys         []string
+		expectedRuns int
+	}{
+		{name: "a mesma chave executa a operação uma vez", keys: []string{"t1", "t1", "t1", "t1"}, expectedRuns: 1},
+		{name: "chaves diferentes executam cada uma a sua", keys: []string{"t1", "t2", "t3", "t4"}, expectedRuns: 4},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			is := NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+			// every operation waits for all of them to start, so a lock shared by every key
+			// would never let them finish
+			started := make(chan struct{}, len(tt.keys))
+			release := make(chan struct{})
+			done := make(chan struct{}, len(tt.keys))
+
+			for _, key := range tt.keys {
+				go func(key string) {
+					_, _, _ = is.Execute(key, "fingerprint", func() ([]byte, bool) {
+						started <- struct{}{}
+						<-release
+						return []byte("{}"), true
+					})
+					done <- struct{}{}
+				}(key)
+			}
+
+			runs := 0
+
+			for runs < tt.expectedRuns {
+				select {
+				case <-started:
+					runs++
+				case <-time.After(time.Second):
+					t.Fatalf("%d of %d operations started", runs, tt.expectedRuns)
+				}
+			}
+
+			close(release)
+
+			for range tt.keys {
+				<-done
+			}
+
+			assert.Len(t, started, 0, "retries under a running key get its response")
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/ports"
+)
+
+// Relay service to deliver the events stored in the outbox to the publisher, at least once
+// and in the order they were stored
+type Relay struct {
+	outbox    ports.Outbox
+	publisher ports.EventPublisher
+	batchSize int
+	retention time.Duration
+	clock     ports.Clock
+	mu        *sync.Mutex
+}
+
+// NewRelay create a new Relay instance, entries are read batchSize at a time and kept for
+// the retention once delivered, measured on the clock
+func NewRelay(o ports.Outbox, p ports.EventPublisher, c ports.Clock, batchSize int, retention time.Duration) Relay {
+	return Relay{outbox: o, publisher: p, clock: c, batchSize: batchSize, retention: retention, mu: &sync.Mutex{}}
+}
+
+// Deliver publish the pending entries, oldest first, and return how many were delivered.
+// It stops at the first entry the publisher fails on, so no event overtakes it, records the
+// failure on the entry and returns it. An entry published but not marked as delivered is
+// published again by the next call
+func (r Relay) Deliver() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivered := 0
+
+	for {
+		pending, err := r.outbox.Pending(r.batchSize)
+		if err != nil {
+			return delivered, err
+		}
+
+		for _, entry := range pending {
+			if err := r.publisher.Publish(entry.Event); err != nil {
+				if markErr := r.outbox.MarkFailed(entry.ID, r.clock.Now(), err); markErr != nil {
+					return delivered, markErr
+				}
+
+				return delivered, err
+			}
+
+			if err := r.outbox.MarkDelivered(entry.ID, r.clock.Now()); err != nil {
+				return delivered, err
+			}
+
+			delivered++
+		}
+
+		if len(pending) < r.batchSize || r.batchSize <= 0 {
+			break
+		}
+	}
+
+	if _, err := r.outbox.Prune(r.clock.Now().Add(-r.retention)); err != nil {
+		return delivered, err
+	}
+
+	return delivered, nil
+}
+
+// Run deliver on every tick of the interval until the context is done, then once more so
+// nothing stored before is left behind. Failures are handed to onError and retried on the
+// next tick
+func (r Relay) Run(ctx context.Context, interval time.Duration, onError func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if _, err := r.Deliver(); err != nil {
+				onError(err)
+			}
+			return
+		case <-ticker.C:
+			if _, err := r.Deliver(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
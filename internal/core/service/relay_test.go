@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/event"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelay_Deliver(t *testing.T) {
+	publishErr := errors.New("broker down")
+
+	testCases := []struct {
+		name              string
+		failOn            string
+		batchSize         int
+		expectedDelivered int
+		expectedErr       error
+		expectedReceived  []string
+		expectedPending   []string
+	}{
+		{
+			name:              "entregando todos os eventos em lotes",
+			batchSize:         2,
+			expectedDelivered: 3,
+			expectedReceived:  []string{"1", "2", "3"},
+			expectedPending:   []string{},
+		},
+		{
+			name:              "parando no primeiro evento que falha",
+			failOn:            "2",
+			batchSize:         10,
+			expectedDelivered: 1,
+			expectedErr:       publishErr,
+			expectedReceived:  []string{"1", "2"},
+			expectedPending:   []string{"2", "3"},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			outbox := repository.NewInMemoryOutbox(clock.NewSystemClock())
+
+			for _, id := range []string{"1", "2", "3"} {
+				_ = outbox.Append(domain.TransactionApproved{AuthorizationID: id})
+			}
+
+			received := []string{}
+
+			bus := event.NewBus()
+			bus.SubscribeAll(func(e domain.Event) error {
+				id := e.(domain.TransactionApproved).AuthorizationID
+				received = append(received, id)
+
+				if id == tt.failOn {
+					return publishErr
+				}
+				return nil
+			})
+
+			delivered, err := NewRelay(outbox, bus, clock.NewSystemClock(), tt.batchSize, time.Hour).Deliver()
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedDelivered, delivered)
+			assert.Equal(t, tt.expectedReceived, received)
+
+			pending, _ := outbox.Pending(0)
+
+			ids := []string{}
+			for _, entry := range pending {
+				ids = append(ids, entry.Event.(domain.TransactionApproved).AuthorizationID)
+			}
+
+			assert.Equal(t, tt.expectedPending, ids)
+
+			if tt.failOn != "" {
+				assert.Equal(t, 1, pending[0].Attempts)
+				assert.Equal(t, publishErr.Error(), pending[0].LastError)
+			}
+		})
+	}
+}
+
+func TestRelay_Deliver_Retries_Failed_Events(t *testing.T) {
+	outbox := repository.NewInMemoryOutbox(clock.NewSystemClock())
+	_ = outbox.Append(domain.TransactionApproved{AuthorizationID: "1"})
+
+	failures := 2
+	received := 0
+
+	bus := event.NewBus()
+	bus.SubscribeAll(func(e domain.Event) error {
+		received++
+
+		if failures > 0 {
+			failures--
+			return errors.New("broker down")
+		}
+		return nil
+	})
+
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(baseTime)
+
+	relay := NewRelay(outbox, bus, fakeClock, 10, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		_, _ = relay.Deliver()
+	}
+
+	pending, _ := outbox.Pending(0)
+
+	assert.Empty(t, pending)
+	assert.Equal(t, 3, received, "the event is published again until it is delivered")
+
+	fakeClock.Advance(2 * time.Hour)
+
+	delivered, err := relay.Deliver()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.ErrorIs(t, outbox.MarkDelivered(1, baseTime), ports.ErrOutboxEntryNotFound, "delivered entries are pruned after the retention")
+}
+
+func TestRelay_Run(t *testing.T) {
+	outbox := repository.NewInMemoryOutbox(clock.NewSystemClock())
+	_ = outbox.Append(domain.TransactionApproved{AuthorizationID: "1"})
+
+	received := 0
+
+	bus := event.NewBus()
+	bus.SubscribeAll(func(e domain.Event) error {
+		received++
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	NewRelay(outbox, bus, clock.NewSystemClock(), 10, time.Hour).Run(ctx, time.Hour, func(err error) {
+		t.Errorf("unexpected error: %v", err)
+	})
+
+	assert.Equal(t, 1, received, "the outbox is drained once more when the context is done")
+}
@@ -1,126 +1,448 @@
 package service
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/authorizer/internal/core/domain"
 	"github.com/authorizer/internal/core/ports"
 )
 
+// Page sizes of the authorization history
+const (
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 500
+)
+
 // Transaction service to process transactions
 type Transaction struct {
-	repo       ports.AccountRepository
-	violations domain.Violations
+	repo              ports.AccountRepository
+	authorizationRepo ports.AuthorizationRepository
+	locker            ports.Locker
+	ids               ports.IDGenerator
+	outbox            ports.Outbox
+	retention         domain.RetentionPolicy
+	ordering          domain.OrderingPolicy
+	clock             ports.Clock
+	observers         []ports.AuthorizationObserver
+}
+
+// NewTransaction create a new Transaction instance, transactions out of order are rejected
+func NewTransaction(
+	r ports.AccountRepository,
+	ar ports.AuthorizationRepository,
+	l ports.Locker,
+	ids ports.IDGenerator,
+	o ports.Outbox,
+	retention domain.RetentionPolicy,
+) Transaction {
+	return Transaction{repo: r, authorizationRepo: ar, locker: l, ids: ids, outbox: o, retention: retention}
+}
+
+// WithObservers return a copy of the service that tells the observers about every authorization,
+// latencies are measured on the clock
+func (t Transaction) WithObservers(c ports.Clock, observers ...ports.AuthorizationObserver) Transaction {
+	t.clock = c
+	t.observers = append(append([]ports.AuthorizationObserver(nil), t.observers...), observers...)
+	return t
+}
+
+// WithOrdering return a copy of the service that handles transactions out of order under the policy
+func (t Transaction) WithOrdering(policy domain.OrderingPolicy) Transaction {
+	t.ordering = policy
+	return t
 }
 
-// NewTransaction create a new Transaction instance
-func NewTransaction(r ports.AccountRepository) Transaction {
-	return Transaction{repo: r, violations: domain.Violations{}}
+// Authorize process domain.Transaction and return the domain.Decision with every check
+// that ran, the error is only set when the account, its history or the outbox could not be read
+// or written. Every transaction with a valid input is recorded, approved or declined, under a
+// new ID, and its events are stored for delivery: an approval with the account change, in the
+// same write, a decline on its own since the account does not change. Observers are told about
+// the outcome, errors included
+func (t Transaction) Authorize(transaction domain.Transaction) (domain.Decision, error) {
+	if len(t.observers) == 0 {
+		return t.authorize(transaction)
+	}
+
+	start := t.clock.Now()
+	decision, err := t.authorize(transaction)
+	latency := t.clock.Now().Sub(start)
+
+	for _, observer := range t.observers {
+		observer.ObserveAuthorization(transaction, decision, err, latency)
+	}
+
+	return decision, err
 }
 
-// Authorize process domain.Transaction and return domain.Account
-func (t *Transaction) Authorize(transaction domain.Transaction) (*domain.Account, domain.Violations) {
-	account, _ := t.repo.Retrieve(transaction.Time)
+func (t Transaction) authorize(transaction domain.Transaction) (domain.Decision, error) {
+	decision := domain.Decision{Checks: validateTransactionInput(transaction)}
+
+	if decision.Violations = failedChecks(decision.Checks); len(decision.Violations) > 0 {
+		return decision, nil
+	}
+
+	unlock := t.locker.Lock(domain.DefaultAccountID)
+	defer unlock()
+
+	account, err := t.repo.Retrieve(transaction.Time)
+
+	if err != nil && !errors.Is(err, ports.ErrAccountNotFound) {
+		return domain.Decision{}, err
+	}
 
 	if account == nil {
-		return nil, domain.Violations{domain.AccountNotInitializedViolation}
+		decision.Checks = append(decision.Checks, domain.NewCheck(
+			domain.AccountCheck,
+			domain.AccountNotInitializedViolation,
+			map[string]interface{}{"initialized": false},
+		))
+		decision.Violations = failedChecks(decision.Checks)
+
+		return t.decline(decision, transaction, 0)
 	}
 
+	decision.Account = account
+	decision.Checks = append(decision.Checks, domain.NewCheck(
+		domain.AccountCheck,
+		"",
+		map[string]interface{}{"initialized": true},
+	))
+
 	if !account.Ledger.ActiveCard {
-		return account, domain.Violations{domain.CardNotActiveViolation}
+		decision.Checks = append(decision.Checks, domain.NewCheck(
+			domain.ActiveCardCheck,
+			domain.CardNotActiveViolation,
+			map[string]interface{}{"active-card": false},
+		))
+		decision.Violations = failedChecks(decision.Checks)
+
+		return t.decline(decision, transaction, account.Ledger.AvailableLimit)
 	}
 
-	violations := t.validate(account, transaction)
+	decision.Checks = append(decision.Checks, domain.NewCheck(
+		domain.ActiveCardCheck,
+		"",
+		map[string]interface{}{"active-card": true},
+	))
+
+	// every transaction is in order when any lateness is accepted, the check is left out then
+	if !t.ordering.AnyLateness {
+		orderCheck, err := t.validateOrder(transaction)
+
+		if err != nil {
+			return domain.Decision{}, err
+		}
 
-	if len(violations) > 0 {
-		return account, violations
+		decision.Checks = append(decision.Checks, orderCheck)
+
+		if !orderCheck.Passed {
+			decision.Violations = failedChecks(decision.Checks)
+
+			return t.decline(decision, transaction, account.Ledger.AvailableLimit)
+		}
 	}
 
-	account.Authorizations = append(account.Authorizations, domain.TransactionAuthorization{
+	checks, err := t.validate(account, transaction)
+
+	if err != nil {
+		return domain.Decision{}, err
+	}
+
+	decision.Checks = append(decision.Checks, checks...)
+
+	if decision.Violations = failedChecks(decision.Checks); len(decision.Violations) > 0 {
+		return t.decline(decision, transaction, account.Ledger.AvailableLimit)
+	}
+
+	authorization := t.newAuthorization(decision, transaction, account.Ledger.AvailableLimit)
+
+	changeAvailable(account, transaction.Amount)
+
+	approved := domain.TransactionApproved{
+		AccountID:       domain.DefaultAccountID,
+		AuthorizationID: authorization.ID,
+		Merchant:        authorization.Merchant,
+		Amount:          authorization.Amount,
+		AvailableLimit:  account.Ledger.AvailableLimit,
+		Time:            authorization.Time,
+	}
+
+	if err := t.repo.Update(*account, approved); err != nil {
+		return domain.Decision{}, err
+	}
+
+	return t.record(decision, authorization)
+}
+
+// GetAuthorization return the authorization recorded under the ID, or the
+// authorization-not-found violation when there is none
+func (t Transaction) GetAuthorization(id string) (*domain.TransactionAuthorization, []string, error) {
+	authorization, err := t.authorizationRepo.Find(id)
+
+	if errors.Is(err, ports.ErrAuthorizationNotFound) {
+		return nil, []string{domain.AuthorizationNotFoundViolation}, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return authorization, []string{}, nil
+}
+
+// ListAuthorizations return a page of the authorization history, oldest first. Pages hold
+// DefaultHistoryLimit authorizations unless the query asks for fewer, up to MaxHistoryLimit
+func (t Transaction) ListAuthorizations(query ports.AuthorizationQuery) (ports.AuthorizationPage, []string, error) {
+	if query.Offset < 0 || query.Limit < 0 || (!query.To.IsZero() && query.To.Before(query.From)) {
+		return ports.AuthorizationPage{}, []string{domain.InvalidQueryViolation}, nil
+	}
+
+	if query.Limit == 0 {
+		query.Limit = DefaultHistoryLimit
+	}
+
+	if query.Limit > MaxHistoryLimit {
+		query.Limit = MaxHistoryLimit
+	}
+
+	page, err := t.authorizationRepo.List(query)
+
+	if err != nil {
+		return ports.AuthorizationPage{}, nil, err
+	}
+
+	return page, []string{}, nil
+}
+
+// newAuthorization build the authorization behind the decision under a new ID
+func (t Transaction) newAuthorization(
+	decision domain.Decision,
+	transaction domain.Transaction,
+	availableLimit int64,
+) domain.TransactionAuthorization {
+	return domain.TransactionAuthorization{
+		ID:             t.ids.NewID(),
 		Merchant:       transaction.Merchant,
 		Amount:         transaction.Amount,
-		AvailableLimit: account.Ledger.AvailableLimit,
+		AvailableLimit: availableLimit,
 		Time:           transaction.Time,
+		Violations:     decision.Violations,
+	}
+}
+
+// decline store the events of a declined decision in the outbox and record it
+func (t Transaction) decline(decision domain.Decision, transaction domain.Transaction, availableLimit int64) (domain.Decision, error) {
+	authorization := t.newAuthorization(decision, transaction, availableLimit)
+
+	if err := t.outbox.Append(declinedEvents(decision, authorization)...); err != nil {
+		return domain.Decision{}, err
+	}
+
+	return t.record(decision, authorization)
+}
+
+// record save the authorization behind the decision and prune the history
+func (t Transaction) record(decision domain.Decision, authorization domain.TransactionAuthorization) (domain.Decision, error) {
+	if err := t.authorizationRepo.Save(authorization); err != nil {
+		return domain.Decision{}, err
+	}
+
+	if before, prune := t.retention.PruneBefore(authorization.Time, t.ordering); prune {
+		if _, err := t.authorizationRepo.Prune(before); err != nil {
+			return domain.Decision{}, err
+		}
+	}
+
+	decision.AuthorizationID = authorization.ID
+
+	return decision, nil
+}
+
+// declinedEvents build RuleTriggered for every failed rule followed by TransactionDeclined
+func declinedEvents(decision domain.Decision, authorization domain.TransactionAuthorization) []domain.Event {
+	var events []domain.Event
+
+	if decision.Account != nil {
+		failed := make(map[string]domain.Check)
+		for _, check := range decision.Checks {
+			if !check.Passed {
+				failed[check.Name] = check
+			}
+		}
+
+		for _, rule := range decision.Account.SpendingControl.Rules {
+			if check, exists := failed[rule.Name]; exists {
+				events = append(events, domain.RuleTriggered{
+					AccountID:       domain.DefaultAccountID,
+					AuthorizationID: authorization.ID,
+					Rule:            rule.Name,
+					Violation:       check.Violation,
+					Time:            authorization.Time,
+				})
+			}
+		}
+	}
+
+	return append(events, domain.TransactionDeclined{
+		AccountID:       domain.DefaultAccountID,
+		AuthorizationID: authorization.ID,
+		Merchant:        authorization.Merchant,
+		Amount:          authorization.Amount,
+		AvailableLimit:  authorization.AvailableLimit,
+		Violations:      authorization.Violations,
+		Time:            authorization.Time,
 	})
+}
 
-	changeAvailable(account, transaction.Amount)
+func (t Transaction) validate(a *domain.Account, transaction domain.Transaction) ([]domain.Check, error) {
+	checks := []domain.Check{validateAvailable(a, transaction.Amount)}
 
-	_ = t.repo.Update(*account)
+	checks = append(checks, evaluateRules(a, transaction)...)
 
-	return account, violations
+	check, err := t.validateDoubledTransaction(transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(checks, check), nil
 }
 
-func (t *Transaction) validate(a *domain.Account, transaction domain.Transaction) domain.Violations {
-	violations := domain.Violations{}
+// validateTransactionInput checks the transaction itself, before any account state is read
+func validateTransactionInput(transaction domain.Transaction) []domain.Check {
+	var amountViolation, merchantViolation, timeViolation string
 
-	violation := validateAvailable(a, transaction.Amount)
-	violations.AddViolation(violation)
+	if transaction.Amount <= 0 {
+		amountViolation = domain.InvalidAmountViolation
+	}
 
-	rulesViolations := evaluateRules(a, transaction)
-	violations.AddViolation(rulesViolations...)
+	if strings.TrimSpace(transaction.Merchant) == "" {
+		merchantViolation = domain.InvalidMerchantViolation
+	}
 
-	violation = validateIdempotencyTransactions(a.Authorizations, transaction)
-	violations.AddViolation(violation)
+	if transaction.Time.IsZero() {
+		timeViolation = domain.InvalidTimeViolation
+	}
+
+	return []domain.Check{
+		domain.NewCheck(domain.AmountCheck, amountViolation, map[string]interface{}{"amount": transaction.Amount}),
+		domain.NewCheck(domain.MerchantCheck, merchantViolation, map[string]interface{}{"merchant": transaction.Merchant}),
+		domain.NewCheck(domain.TimeCheck, timeViolation, map[string]interface{}{"time": transaction.Time}),
+	}
+}
+
+// failedChecks collect the violations of the checks that did not pass, in order
+func failedChecks(checks []domain.Check) domain.Violations {
+	violations := domain.Violations{}
+
+	for _, check := range checks {
+		violations.AddViolation(check.Violation)
+	}
 
 	return violations
 }
 
-func validateAvailable(account *domain.Account, amount int64) string {
+func validateAvailable(account *domain.Account, amount int64) domain.Check {
+	var violation string
+
 	if account.Ledger.AvailableLimit-amount < 0 {
-		return domain.InsufficientLimitViolation
+		violation = domain.InsufficientLimitViolation
 	}
 
-	return ""
+	return domain.NewCheck(domain.LedgerCheck, violation, map[string]interface{}{
+		"available-limit": account.Ledger.AvailableLimit,
+		"amount":          amount,
+		"remaining":       account.Ledger.AvailableLimit - amount,
+	})
 }
 
 func changeAvailable(account *domain.Account, amount int64) {
 	account.Ledger.AvailableLimit = account.Ledger.AvailableLimit - amount
 }
 
-func validateIdempotencyTransactions(authorizations []domain.TransactionAuthorization, t domain.Transaction) string {
-	lenAuthorizations := len(authorizations)
+// validateOrder checks the transaction against the latest approved one under the ordering
+// policy, declines leave no trace in the history the checks read so they do not move it
+func (t Transaction) validateOrder(transaction domain.Transaction) (domain.Check, error) {
+	details := map[string]interface{}{"lateness": t.ordering.Lateness.String()}
+
+	latest, err := t.authorizationRepo.LatestApproved()
 
-	if lenAuthorizations == 0 {
-		return ""
+	if errors.Is(err, ports.ErrAuthorizationNotFound) {
+		return domain.NewCheck(domain.OrderCheck, "", details), nil
 	}
 
-	return validateDoubledTransactions(authorizations, t)
+	if err != nil {
+		return domain.Check{}, err
+	}
+
+	details["latest-time"] = latest.Time
+
+	if t.ordering.Accepts(latest.Time, transaction.Time) {
+		return domain.NewCheck(domain.OrderCheck, "", details), nil
+	}
+
+	return domain.NewCheck(domain.OrderCheck, domain.TransactionOutOfOrderViolation, details), nil
 }
 
-func validateDoubledTransactions(authorizations []domain.TransactionAuthorization, t domain.Transaction) string {
-	lenAuthorizations := len(authorizations)
+// validateDoubledTransaction compares the transaction with the approved one for the same merchant
+// and amount nearest in time, before or after it, so late transactions are compared too
+func (t Transaction) validateDoubledTransaction(transaction domain.Transaction) (domain.Check, error) {
+	details := map[string]interface{}{"window": domain.DoubledTransactionWindow.String()}
 
-	for i := lenAuthorizations - 1; i >= 0; i-- {
-		if t.Time.Sub(authorizations[i].Time).Minutes() > 2 {
-			return ""
-		}
+	nearest, err := t.authorizationRepo.FindNearest(transaction.Merchant, transaction.Amount, transaction.Time)
 
-		if t.Merchant == authorizations[i].Merchant && t.Amount == authorizations[i].Amount {
-			return domain.DoubledTransactionViolation
-		}
+	if errors.Is(err, ports.ErrAuthorizationNotFound) {
+		return domain.NewCheck(domain.DoubledTransactionCheck, "", details), nil
 	}
 
-	return ""
+	if err != nil {
+		return domain.Check{}, err
+	}
+
+	elapsed := transaction.Time.Sub(nearest.Time)
+	if elapsed < 0 {
+		elapsed = -elapsed
+	}
+
+	details["nearest-time"] = nearest.Time
+	details["elapsed"] = elapsed.String()
+
+	if elapsed > domain.DoubledTransactionWindow {
+		return domain.NewCheck(domain.DoubledTransactionCheck, "", details), nil
+	}
+
+	return domain.NewCheck(domain.DoubledTransactionCheck, domain.DoubledTransactionViolation, details), nil
 }
 
-func evaluateRules(account *domain.Account, transaction domain.Transaction) []string {
-	var violations []string
+func evaluateRules(account *domain.Account, transaction domain.Transaction) []domain.Check {
+	checks := make([]domain.Check, 0, len(account.SpendingControl.Rules))
 
 	for _, rule := range account.SpendingControl.Rules {
-		violation := evaluateRule(&rule, transaction)
-
-		if violation != "" {
-			violations = append(violations, violation)
-		}
+		checks = append(checks, evaluateRule(&rule, transaction))
 	}
 
-	return violations
+	return checks
 }
 
-func evaluateRule(rule *domain.Rule, transaction domain.Transaction) string {
+func evaluateRule(rule *domain.Rule, transaction domain.Transaction) domain.Check {
 	rule.Accumulator.AddSpend(transaction.Amount)
 
-	if rule.Type == "usage-limit" && rule.Accumulator.CurrentPeriodUsed > rule.UsageLimit {
-		return rule.RuleViolation
+	details := map[string]interface{}{
+		"type":         rule.Type,
+		"period-used":  rule.Accumulator.CurrentPeriodUsed,
+		"period-spend": rule.Accumulator.CurrentPeriodSpend,
+		"period-ends":  rule.Accumulator.PeriodEndsDate,
+	}
+
+	if rule.Type != domain.UsageLimitRule {
+		return domain.NewCheck(rule.Name, "", details)
+	}
+
+	details["usage-limit"] = rule.UsageLimit
+
+	if rule.Accumulator.CurrentPeriodUsed > rule.UsageLimit {
+		return domain.NewCheck(rule.Name, rule.RuleViolation, details)
 	}
 
-	return ""
+	return domain.NewCheck(rule.Name, "", details)
 }
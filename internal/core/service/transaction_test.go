@@ -1,7 +1,14 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
 	"github.com/authorizer/internal/driven/repository"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
@@ -11,10 +18,12 @@ import (
 
 func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 	testCases := []struct {
-		name            string
-		mockAccount     domain.Account
-		transaction     domain.Transaction
-		expectedAccount domain.Account
+		name                   string
+		mockAccount            domain.Account
+		authorizations         []domain.TransactionAuthorization
+		transaction            domain.Transaction
+		expectedAccount        domain.Account
+		expectedAuthorizations []domain.TransactionAuthorization
 	}{
 		{
 			name: "Processando uma transação com sucesso",
@@ -45,7 +54,16 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{},
+			},
+			expectedAuthorizations: []domain.TransactionAuthorization{
+				{
+					ID:             "00000000000000000001",
+					Merchant:       "xablau testador",
+					Amount:         100,
+					AvailableLimit: 200,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+					Violations:     domain.Violations{},
+				},
 			},
 			expectedAccount: domain.Account{
 				Ledger: domain.Ledger{
@@ -69,14 +87,6 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "xablau testador",
-						Amount:         100,
-						AvailableLimit: 200,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-				},
 			},
 		},
 		{
@@ -86,6 +96,20 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 				Amount:   25,
 				Time:     time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
 			},
+			authorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 500,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 475,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+			},
 			mockAccount: domain.Account{
 				Ledger: domain.Ledger{
 					ActiveCard:     true,
@@ -108,19 +132,27 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 500,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 475,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
+			},
+			expectedAuthorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 500,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 475,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+				{
+					ID:             "00000000000000000001",
+					Merchant:       "Merchant3",
+					Amount:         25,
+					AvailableLimit: 450,
+					Time:           time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
+					Violations:     domain.Violations{},
 				},
 			},
 			expectedAccount: domain.Account{
@@ -145,26 +177,6 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 500,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 475,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant3",
-						Amount:         25,
-						AvailableLimit: 450,
-						Time:           time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
-					},
-				},
 			},
 		},
 		{
@@ -174,6 +186,20 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 				Amount:   25,
 				Time:     time.Date(2021, 10, 10, 10, 10, 0, 0, time.Local),
 			},
+			authorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 500,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 475,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+			},
 			mockAccount: domain.Account{
 				Ledger: domain.Ledger{
 					ActiveCard:     true,
@@ -196,19 +222,27 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 500,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 475,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
+			},
+			expectedAuthorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 500,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 475,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+				{
+					ID:             "00000000000000000001",
+					Merchant:       "Merchant3",
+					Amount:         25,
+					AvailableLimit: 450,
+					Time:           time.Date(2021, 10, 10, 10, 10, 0, 0, time.Local),
+					Violations:     domain.Violations{},
 				},
 			},
 			expectedAccount: domain.Account{
@@ -233,26 +267,6 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 500,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 475,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant3",
-						Amount:         25,
-						AvailableLimit: 450,
-						Time:           time.Date(2021, 10, 10, 10, 10, 0, 0, time.Local),
-					},
-				},
 			},
 		},
 	}
@@ -265,13 +279,20 @@ func TestTransaction_Authorize_Without_Violations(t *testing.T) {
 			accountRepoMock := repository.NewMockAccountRepository(ctrl)
 
 			accountRepoMock.EXPECT().Retrieve(tt.transaction.Time).Return(&tt.mockAccount, nil)
-			accountRepoMock.EXPECT().Update(tt.expectedAccount).Return(nil)
+			accountRepoMock.EXPECT().Update(tt.expectedAccount, gomock.AssignableToTypeOf(domain.TransactionApproved{})).Return(nil)
+
+			authorizationRepo := seedAuthorizations(tt.authorizations)
+
+			ts := NewTransaction(accountRepoMock, authorizationRepo, lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{})
 
-			ts := NewTransaction(accountRepoMock)
+			decision, err := ts.Authorize(tt.transaction)
 
-			account, _ := ts.Authorize(tt.transaction)
+			assert.NoError(t, err)
 
-			assert.Equal(t, account.Ledger.AvailableLimit, tt.expectedAccount.Ledger.AvailableLimit)
+			assert.Equal(t, decision.Account.Ledger.AvailableLimit, tt.expectedAccount.Ledger.AvailableLimit)
+
+			page, _ := authorizationRepo.List(ports.AuthorizationQuery{})
+			assert.Equal(t, tt.expectedAuthorizations, page.Authorizations)
 		})
 	}
 }
@@ -280,6 +301,7 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 	testCases := []struct {
 		name               string
 		mockAccount        *domain.Account
+		authorizations     []domain.TransactionAuthorization
 		transaction        domain.Transaction
 		expectedViolations domain.Violations
 	}{
@@ -322,7 +344,6 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{},
 			},
 			expectedViolations: domain.Violations{"card-not-active"},
 		},
@@ -355,7 +376,6 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{},
 			},
 			expectedViolations: domain.Violations{"insufficient-limit"},
 		},
@@ -366,6 +386,26 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 				Amount:   25,
 				Time:     time.Date(2021, 10, 10, 10, 1, 30, 0, time.Local),
 			},
+			authorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 225,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 200,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant3",
+					Amount:         25,
+					AvailableLimit: 175,
+					Time:           time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
+				},
+			},
 			mockAccount: &domain.Account{
 				Ledger: domain.Ledger{
 					ActiveCard:     true,
@@ -388,26 +428,6 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 225,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 200,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant3",
-						Amount:         25,
-						AvailableLimit: 175,
-						Time:           time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
-					},
-				},
 			},
 			expectedViolations: domain.Violations{"high-frequency-small-interval"},
 		},
@@ -418,6 +438,20 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 				Amount:   25,
 				Time:     time.Date(2021, 10, 10, 10, 1, 30, 0, time.Local),
 			},
+			authorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 225,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 200,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+			},
 			mockAccount: &domain.Account{
 				Ledger: domain.Ledger{
 					ActiveCard:     true,
@@ -440,20 +474,6 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 225,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 200,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
-				},
 			},
 			expectedViolations: domain.Violations{"doubled-transaction"},
 		},
@@ -464,6 +484,26 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 				Amount:   100,
 				Time:     time.Date(2021, 10, 10, 10, 1, 30, 0, time.Local),
 			},
+			authorizations: []domain.TransactionAuthorization{
+				{
+					Merchant:       "Merchant1",
+					Amount:         25,
+					AvailableLimit: 225,
+					Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant2",
+					Amount:         25,
+					AvailableLimit: 200,
+					Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
+				},
+				{
+					Merchant:       "Merchant3",
+					Amount:         100,
+					AvailableLimit: 200,
+					Time:           time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
+				},
+			},
 			mockAccount: &domain.Account{
 				Ledger: domain.Ledger{
 					ActiveCard:     true,
@@ -486,26 +526,6 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 						},
 					},
 				},
-				Authorizations: []domain.TransactionAuthorization{
-					{
-						Merchant:       "Merchant1",
-						Amount:         25,
-						AvailableLimit: 225,
-						Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant2",
-						Amount:         25,
-						AvailableLimit: 200,
-						Time:           time.Date(2021, 10, 10, 10, 0, 30, 0, time.Local),
-					},
-					{
-						Merchant:       "Merchant3",
-						Amount:         100,
-						AvailableLimit: 200,
-						Time:           time.Date(2021, 10, 10, 10, 1, 0, 0, time.Local),
-					},
-				},
 			},
 			expectedViolations: domain.Violations{"insufficient-limit", "high-frequency-small-interval", "doubled-transaction"},
 		},
@@ -518,13 +538,692 @@ func TestTransaction_Authorize_With_Violations(t *testing.T) {
 
 			accountRepoMock := repository.NewMockAccountRepository(ctrl)
 
-			accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(tt.mockAccount, nil)
+			var retrieveErr error
+			if tt.mockAccount == nil {
+				retrieveErr = ports.ErrAccountNotFound
+			}
+
+			accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(tt.mockAccount, retrieveErr)
+
+			ts := NewTransaction(accountRepoMock, seedAuthorizations(tt.authorizations), lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{})
+
+			decision, err := ts.Authorize(tt.transaction)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedViolations, decision.Violations)
+		})
+	}
+}
+
+func TestTransaction_Authorize_With_Storage_Errors(t *testing.T) {
+	storageErr := &ports.StorageError{Op: "update", Err: errors.New("disk full")}
+
+	mockAccount := func() *domain.Account {
+		return &domain.Account{
+			Ledger: domain.Ledger{
+				ActiveCard:     true,
+				MaxLimit:       200,
+				AvailableLimit: 200,
+			},
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		setupMock func(m *repository.MockAccountRepository, am *repository.MockAuthorizationRepository)
+	}{
+		{
+			name: "Falha ao buscar a conta",
+			setupMock: func(m *repository.MockAccountRepository, am *repository.MockAuthorizationRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(nil, storageErr)
+			},
+		},
+		{
+			name: "Falha ao buscar a última autorização",
+			setupMock: func(m *repository.MockAccountRepository, am *repository.MockAuthorizationRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(mockAccount(), nil)
+				am.EXPECT().LatestApproved().Return(nil, storageErr)
+			},
+		},
+		{
+			name: "Falha ao buscar o histórico de autorizações",
+			setupMock: func(m *repository.MockAccountRepository, am *repository.MockAuthorizationRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(mockAccount(), nil)
+				am.EXPECT().LatestApproved().Return(nil, ports.ErrAuthorizationNotFound)
+				am.EXPECT().FindNearest("xablau testador", int64(100), gomock.Any()).Return(nil, storageErr)
+			},
+		},
+		{
+			name: "Falha ao atualizar a conta",
+			setupMock: func(m *repository.MockAccountRepository, am *repository.MockAuthorizationRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(mockAccount(), nil)
+				am.EXPECT().LatestApproved().Return(nil, ports.ErrAuthorizationNotFound)
+				am.EXPECT().FindNearest("xablau testador", int64(100), gomock.Any()).Return(nil, ports.ErrAuthorizationNotFound)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(storageErr)
+			},
+		},
+		{
+			name: "Falha ao salvar a autorização",
+			setupMock: func(m *repository.MockAccountRepository, am *repository.MockAuthorizationRepository) {
+				m.EXPECT().Retrieve(gomock.Any()).Return(mockAccount(), nil)
+				am.EXPECT().LatestApproved().Return(nil, ports.ErrAuthorizationNotFound)
+				am.EXPECT().FindNearest("xablau testador", int64(100), gomock.Any()).Return(nil, ports.ErrAuthorizationNotFound)
+				m.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+				am.EXPECT().Save(gomock.Any()).Return(storageErr)
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepoMock := repository.NewMockAccountRepository(ctrl)
+			authorizationRepoMock := repository.NewMockAuthorizationRepository(ctrl)
+			tt.setupMock(accountRepoMock, authorizationRepoMock)
+
+			ts := NewTransaction(accountRepoMock, authorizationRepoMock, lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{})
+
+			decision, err := ts.Authorize(domain.Transaction{
+				Merchant: "xablau testador",
+				Amount:   100,
+				Time:     time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+			})
+
+			assert.Nil(t, decision.Account)
+			assert.Empty(t, decision.Violations)
+			assert.ErrorIs(t, err, storageErr)
+		})
+	}
+}
+
+func TestTransaction_Authorize_With_Invalid_Input(t *testing.T) {
+	validTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name               string
+		transaction        domain.Transaction
+		expectedViolations domain.Violations
+	}{
+		{
+			name:               "Transação com valor zerado",
+			transaction:        domain.Transaction{Merchant: "xablau testador", Amount: 0, Time: validTime},
+			expectedViolations: domain.Violations{domain.InvalidAmountViolation},
+		},
+		{
+			name:               "Transação com valor negativo",
+			transaction:        domain.Transaction{Merchant: "xablau testador", Amount: -10, Time: validTime},
+			expectedViolations: domain.Violations{domain.InvalidAmountViolation},
+		},
+		{
+			name:               "Transação sem estabelecimento",
+			transaction:        domain.Transaction{Merchant: "  ", Amount: 100, Time: validTime},
+			expectedViolations: domain.Violations{domain.InvalidMerchantViolation},
+		},
+		{
+			name:               "Transação sem horário",
+			transaction:        domain.Transaction{Merchant: "xablau testador", Amount: 100},
+			expectedViolations: domain.Violations{domain.InvalidTimeViolation},
+		},
+		{
+			name:        "Transação com todos os campos inválidos",
+			transaction: domain.Transaction{Amount: -1},
+			expectedViolations: domain.Violations{
+				domain.InvalidAmountViolation,
+				domain.InvalidMerchantViolation,
+				domain.InvalidTimeViolation,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// no expectations: invalid input must not touch any state
+			accountRepoMock := repository.NewMockAccountRepository(ctrl)
+			authorizationRepoMock := repository.NewMockAuthorizationRepository(ctrl)
+
+			ts := NewTransaction(accountRepoMock, authorizationRepoMock, lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{})
+
+			decision, err := ts.Authorize(tt.transaction)
+
+			assert.NoError(t, err)
+			assert.Nil(t, decision.Account)
+			assert.Equal(t, tt.expectedViolations, decision.Violations)
+		})
+	}
+}
+
+func TestTransaction_Authorize_Checks(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	as := NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+	_, _, _ = as.InitAccount(true, 100)
+
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		_, _ = ts.Authorize(domain.Transaction{
+			Merchant: fmt.Sprintf("Merchant%d", i),
+			Amount:   10,
+			Time:     baseTime.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	decision, err := ts.Authorize(domain.Transaction{
+		Merchant: "Merchant0",
+		Amount:   10,
+		Time:     baseTime.Add(3 * time.Second),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Violations{"high-frequency-small-interval", domain.DoubledTransactionViolation}, decision.Violations)
+
+	names := make([]string, 0, len(decision.Checks))
+	for _, check := range decision.Checks {
+		names = append(names, check.Name)
+	}
+
+	assert.Equal(t, []string{
+		domain.AmountCheck,
+		domain.MerchantCheck,
+		domain.TimeCheck,
+		domain.AccountCheck,
+		domain.ActiveCardCheck,
+		domain.OrderCheck,
+		domain.LedgerCheck,
+		"max transactions in 2 minutes",
+		domain.DoubledTransactionCheck,
+	}, names)
+
+	order := decision.Checks[5]
+	assert.True(t, order.Passed)
+	assert.Equal(t, baseTime.Add(2*time.Second), order.Details["latest-time"])
+
+	ledger := decision.Checks[6]
+	assert.True(t, ledger.Passed)
+	assert.Equal(t, int64(60), ledger.Details["remaining"])
+
+	rule := decision.Checks[7]
+	assert.False(t, rule.Passed)
+	assert.Equal(t, "high-frequency-small-interval", rule.Violation)
+	assert.Equal(t, int64(4), rule.Details["period-used"])
+	assert.Equal(t, int64(3), rule.Details["usage-limit"])
+	assert.Equal(t, baseTime.Add(2*time.Minute), rule.Details["period-ends"])
+
+	doubled := decision.Checks[8]
+	assert.False(t, doubled.Passed)
+	assert.Equal(t, "3s", doubled.Details["elapsed"])
+	assert.Equal(t, baseTime, doubled.Details["nearest-time"])
+}
+
+func TestTransaction_Authorize_Out_Of_Order(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		policy             domain.OrderingPolicy
+		transaction        domain.Transaction
+		expectedViolations domain.Violations
+	}{
+		{
+			name:               "Transação atrasada rejeitada sem tolerância",
+			policy:             domain.OrderingPolicy{},
+			transaction:        domain.Transaction{Merchant: "Subway", Amount: 10, Time: baseTime.Add(4 * time.Minute)},
+			expectedViolations: domain.Violations{domain.TransactionOutOfOrderViolation},
+		},
+		{
+			name:               "Transação atrasada aceita dentro da tolerância",
+			policy:             domain.OrderingPolicy{Lateness: time.Minute},
+			transaction:        domain.Transaction{Merchant: "Subway", Amount: 10, Time: baseTime.Add(4 * time.Minute)},
+			expectedViolations: domain.Violations{},
+		},
+		{
+			name:               "Transação atrasada além da tolerância",
+			policy:             domain.OrderingPolicy{Lateness: time.Minute},
+			transaction:        domain.Transaction{Merchant: "Subway", Amount: 10, Time: baseTime.Add(3 * time.Minute)},
+			expectedViolations: domain.Violations{domain.TransactionOutOfOrderViolation},
+		},
+		{
+			name:               "Transação atrasada duplicada da autorização mais próxima",
+			policy:             domain.OrderingPolicy{Lateness: 10 * time.Minute},
+			transaction:        domain.Transaction{Merchant: "Burger King", Amount: 20, Time: baseTime.Add(30 * time.Second)},
+			expectedViolations: domain.Violations{domain.DoubledTransactionViolation},
+		},
+		{
+			name:               "Transação depois de uma recusada com data futura",
+			policy:             domain.OrderingPolicy{},
+			transaction:        domain.Transaction{Merchant: "Subway", Amount: 10, Time: baseTime.Add(6 * time.Minute)},
+			expectedViolations: domain.Violations{},
+		},
+		{
+			name:               "Transação atrasada aceita em qualquer atraso",
+			policy:             domain.OrderingPolicy{AnyLateness: true},
+			transaction:        domain.Transaction{Merchant: "Subway", Amount: 10, Time: baseTime.Add(-time.Hour)},
+			expectedViolations: domain.Violations{},
+		},
+		{
+			name:               "Transação atrasada longe de qualquer autorização igual",
+			policy:             domain.OrderingPolicy{Lateness: 10 * time.Minute},
+			transaction:        domain.Transaction{Merchant: "Burger King", Amount: 20, Time: baseTime.Add(-5 * time.Minute)},
+			expectedViolations: domain.Violations{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+			locker := lock.NewInMemoryLocker()
+
+			_, _, _ = NewAccount(accountRepo, locker, clock.NewSystemClock()).InitAccount(true, 1000)
+
+			ts := NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{}).
+				WithOrdering(tt.policy)
+
+			_, _ = ts.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 20, Time: baseTime})
+			_, _ = ts.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 20, Time: baseTime.Add(5 * time.Minute)})
+			// declined for insufficient limit, it must not move the latest transaction to 2099
+			_, _ = ts.Authorize(domain.Transaction{Merchant: "Vivara", Amount: 5000, Time: time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)})
+
+			decision, err := ts.Authorize(tt.transaction)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedViolations, decision.Violations)
+		})
+	}
+}
+
+type observedAuthorization struct {
+	transaction domain.Transaction
+	decision    domain.Decision
+	err         error
+}
+
+type authorizationRecorder struct {
+	observed []observedAuthorization
+}
+
+func (r *authorizationRecorder) ObserveAuthorization(transaction domain.Transaction, decision domain.Decision, err error, _ time.Duration) {
+	r.observed = append(r.observed, observedAuthorization{transaction: transaction, decision: decision, err: err})
+}
+
+func TestTransaction_Authorize_Observers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	storageErr := &ports.StorageError{Op: "retrieve", Err: errors.New("connection refused")}
+
+	accountRepoMock := repository.NewMockAccountRepository(ctrl)
+	accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(nil, storageErr)
+
+	recorder := &authorizationRecorder{}
+
+	ts := NewTransaction(accountRepoMock, repository.NewAuthorizationRepository(), lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{}).
+		WithObservers(clock.NewSystemClock(), recorder)
+
+	invalid := domain.Transaction{Merchant: "Burger King"}
+	valid := domain.Transaction{Merchant: "Burger King", Amount: 10, Time: time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)}
+
+	_, _ = ts.Authorize(invalid)
+	_, _ = ts.Authorize(valid)
+
+	assert.Len(t, recorder.observed, 2)
+	assert.Equal(t, invalid, recorder.observed[0].transaction)
+	assert.Equal(t, domain.Violations{domain.InvalidAmountViolation, domain.InvalidTimeViolation}, recorder.observed[0].decision.Violations)
+	assert.NoError(t, recorder.observed[0].err)
+	assert.Equal(t, valid, recorder.observed[1].transaction)
+	assert.ErrorIs(t, recorder.observed[1].err, storageErr)
+}
+
+func TestTransaction_Authorize_Checks_Stop_At_Card_Not_Active(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	_, _, _ = NewAccount(accountRepo, locker, clock.NewSystemClock()).InitAccount(false, 100)
+
+	ts := NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+	decision, err := ts.Authorize(domain.Transaction{
+		Merchant: "Burger King",
+		Amount:   10,
+		Time:     time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Violations{domain.CardNotActiveViolation}, decision.Violations)
+	assert.Len(t, decision.Checks, 5)
+	assert.Equal(t, domain.ActiveCardCheck, decision.Checks[4].Name)
+	assert.False(t, decision.Checks[4].Passed)
+}
+
+func TestTransaction_Authorize_Records_Declined(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	authorizationRepo := repository.NewAuthorizationRepository()
+	locker := lock.NewInMemoryLocker()
+
+	_, _, _ = NewAccount(accountRepo, locker, clock.NewSystemClock()).InitAccount(true, 10)
+
+	ts := NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+	transactionTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	decision, err := ts.Authorize(domain.Transaction{Merchant: "Vivara", Amount: 20, Time: transactionTime})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "00000000000000000001", decision.AuthorizationID)
+
+	invalid, err := ts.Authorize(domain.Transaction{Merchant: "Vivara", Amount: -20, Time: transactionTime})
+
+	assert.NoError(t, err)
+	assert.Empty(t, invalid.AuthorizationID)
+
+	page, _ := authorizationRepo.List(ports.AuthorizationQuery{})
+
+	assert.Equal(t, []domain.TransactionAuthorization{
+		{
+			ID:             "00000000000000000001",
+			Merchant:       "Vivara",
+			Amount:         20,
+			AvailableLimit: 10,
+			Time:           transactionTime,
+			Violations:     domain.Violations{domain.InsufficientLimitViolation},
+		},
+	}, page.Authorizations)
+}
+
+func TestTransaction_Authorize_Stores_Events(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	_, _, _ = NewAccount(accountRepo, locker, clock.NewSystemClock()).InitAccount(true, 100)
+
+	ts := NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		_, _ = ts.Authorize(domain.Transaction{
+			Merchant: fmt.Sprintf("Merchant%d", i),
+			Amount:   10,
+			Time:     baseTime.Add(time.Duration(i) * time.Second),
+		})
+	}
+
+	_, _ = ts.Authorize(domain.Transaction{Merchant: "Merchant0", Amount: 10, Time: baseTime.Add(3 * time.Second)})
+	_, _ = ts.Authorize(domain.Transaction{Merchant: "Merchant0", Amount: -10, Time: baseTime.Add(4 * time.Second)})
+
+	pending, _ := accountRepo.Outbox().Pending(0)
+
+	assert.Len(t, pending, 6, "invalid input stores nothing")
+
+	assert.Equal(t, domain.TransactionApproved{
+		AccountID:       domain.DefaultAccountID,
+		AuthorizationID: "00000000000000000001",
+		Merchant:        "Merchant0",
+		Amount:          10,
+		AvailableLimit:  90,
+		Time:            baseTime,
+	}, pending[1].Event)
+
+	assert.Equal(t, domain.RuleTriggered{
+		AccountID:       domain.DefaultAccountID,
+		AuthorizationID: "00000000000000000004",
+		Rule:            "max transactions in 2 minutes",
+		Violation:       "high-frequency-small-interval",
+		Time:            baseTime.Add(3 * time.Second),
+	}, pending[4].Event)
+
+	assert.Equal(t, domain.TransactionDeclined{
+		AccountID:       domain.DefaultAccountID,
+		AuthorizationID: "00000000000000000004",
+		Merchant:        "Merchant0",
+		Amount:          10,
+		AvailableLimit:  70,
+		Violations:      domain.Violations{"high-frequency-small-interval", domain.DoubledTransactionViolation},
+		Time:            baseTime.Add(3 * time.Second),
+	}, pending[5].Event)
+}
+
+func TestTransaction_Authorize_With_Event_Sourced_Account(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	transactions := []domain.Transaction{
+		{Merchant: "Burger King", Amount: 20, Time: baseTime},
+		{Merchant: "Habbib's", Amount: 20, Time: baseTime.Add(10 * time.Second)},
+		{Merchant: "Burger King", Amount: 20, Time: baseTime.Add(20 * time.Second)},
+		{Merchant: "McDonald's", Amount: 20, Time: baseTime.Add(30 * time.Second)},
+		{Merchant: "Vivara", Amount: 500, Time: baseTime.Add(3 * time.Minute)},
+		{Merchant: "Samsung", Amount: 30, Time: baseTime.Add(4 * time.Minute)},
+	}
+
+	snapshotRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	eventRepo := repository.NewEventSourcedAccountRepository(clock.NewSystemClock(), 2)
+
+	locker := lock.NewInMemoryLocker()
 
-			ts := NewTransaction(accountRepoMock)
+	_, _, _ = NewAccount(snapshotRepo, locker, clock.NewSystemClock()).InitAccount(true, 100)
+	_, _, _ = NewAccount(eventRepo, locker, clock.NewSystemClock()).InitAccount(true, 100)
 
-			_, violations := ts.Authorize(tt.transaction)
+	snapshotHistory := repository.NewAuthorizationRepository()
 
+	snapshotTS := NewTransaction(snapshotRepo, snapshotHistory, locker, identifier.NewSequentialGenerator(), snapshotRepo.Outbox(), domain.RetentionPolicy{})
+	eventTS := NewTransaction(eventRepo, eventRepo.History(), locker, identifier.NewSequentialGenerator(), eventRepo, domain.RetentionPolicy{})
+
+	for _, transaction := range transactions {
+		expected, _ := snapshotTS.Authorize(transaction)
+		decision, err := eventTS.Authorize(transaction)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, decision)
+	}
+
+	expected, _ := snapshotRepo.Retrieve(baseTime.Add(5 * time.Minute))
+	account, _ := eventRepo.Retrieve(baseTime.Add(5 * time.Minute))
+
+	assert.Equal(t, expected, account, "folding the events gives the account stored as a snapshot")
+
+	expectedHistory, _ := snapshotHistory.List(ports.AuthorizationQuery{})
+	history, _, _ := eventTS.ListAuthorizations(ports.AuthorizationQuery{})
+
+	assert.Equal(t, expectedHistory.Authorizations, history.Authorizations, "projecting the events gives the history saved apart")
+
+	authorization, violations, _ := eventTS.GetAuthorization("00000000000000000005")
+	assert.Empty(t, violations)
+	assert.Equal(t, "Vivara", authorization.Merchant)
+}
+
+func TestTransaction_GetAuthorization(t *testing.T) {
+	storageErr := &ports.StorageError{Op: "find authorization", Err: errors.New("connection refused")}
+
+	authorization := domain.TransactionAuthorization{
+		ID:       "00000000000000000001",
+		Merchant: "Burger King",
+		Amount:   20,
+		Time:     time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name                  string
+		findResult            *domain.TransactionAuthorization
+		findErr               error
+		expectedAuthorization *domain.TransactionAuthorization
+		expectedViolations    []string
+		expectedErr           error
+	}{
+		{
+			name:                  "Consultando uma autorização existente",
+			findResult:            &authorization,
+			expectedAuthorization: &authorization,
+			expectedViolations:    []string{},
+		},
+		{
+			name:               "Consultando uma autorização inexistente",
+			findErr:            ports.ErrAuthorizationNotFound,
+			expectedViolations: []string{domain.AuthorizationNotFoundViolation},
+		},
+		{
+			name:        "Falha ao buscar a autorização",
+			findErr:     storageErr,
+			expectedErr: storageErr,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			authorizationRepoMock := repository.NewMockAuthorizationRepository(ctrl)
+			authorizationRepoMock.EXPECT().Find(authorization.ID).Return(tt.findResult, tt.findErr)
+
+			ts := NewTransaction(repository.NewMockAccountRepository(ctrl), authorizationRepoMock, lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{})
+
+			result, violations, err := ts.GetAuthorization(authorization.ID)
+
+			assert.Equal(t, tt.expectedAuthorization, result)
 			assert.Equal(t, tt.expectedViolations, violations)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransaction_ListAuthorizations(t *testing.T) {
+	authorizations := make([]domain.TransactionAuthorization, 0, MaxHistoryLimit+10)
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < MaxHistoryLimit+10; i++ {
+		authorizations = append(authorizations, domain.TransactionAuthorization{
+			Merchant: "Merchant1",
+			Amount:   10,
+			Time:     baseTime.Add(time.Duration(i) * time.Second),
 		})
 	}
+
+	ts := NewTransaction(nil, seedAuthorizations(authorizations), lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{})
+
+	testCases := []struct {
+		name               string
+		query              ports.AuthorizationQuery
+		expectedLength     int
+		expectedLimit      int
+		expectedViolations []string
+	}{
+		{
+			name:               "listando com o tamanho de página padrão",
+			query:              ports.AuthorizationQuery{},
+			expectedLength:     DefaultHistoryLimit,
+			expectedLimit:      DefaultHistoryLimit,
+			expectedViolations: []string{},
+		},
+		{
+			name:               "listando além do tamanho máximo de página",
+			query:              ports.AuthorizationQuery{Limit: MaxHistoryLimit * 2},
+			expectedLength:     MaxHistoryLimit,
+			expectedLimit:      MaxHistoryLimit,
+			expectedViolations: []string{},
+		},
+		{
+			name:               "listando a última página",
+			query:              ports.AuthorizationQuery{Offset: MaxHistoryLimit, Limit: 20},
+			expectedLength:     10,
+			expectedLimit:      20,
+			expectedViolations: []string{},
+		},
+		{
+			name:               "listando com paginação negativa",
+			query:              ports.AuthorizationQuery{Offset: -1},
+			expectedViolations: []string{domain.InvalidQueryViolation},
+		},
+		{
+			name:               "listando com janela de tempo invertida",
+			query:              ports.AuthorizationQuery{From: baseTime.Add(time.Minute), To: baseTime},
+			expectedViolations: []string{domain.InvalidQueryViolation},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			page, violations, err := ts.ListAuthorizations(tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedViolations, violations)
+			assert.Len(t, page.Authorizations, tt.expectedLength)
+			assert.Equal(t, tt.expectedLimit, page.Limit)
+		})
+	}
+}
+
+func TestTransaction_Authorize_Prunes_History(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepoMock := repository.NewMockAccountRepository(ctrl)
+	accountRepoMock.EXPECT().Retrieve(gomock.Any()).Return(&domain.Account{
+		Ledger: domain.Ledger{
+			ActiveCard:     true,
+			MaxLimit:       200,
+			AvailableLimit: 200,
+		},
+	}, nil)
+	accountRepoMock.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil)
+
+	authorizationRepo := seedAuthorizations([]domain.TransactionAuthorization{
+		{Merchant: "Merchant1", Amount: 25, Time: time.Date(2021, 10, 10, 9, 0, 0, 0, time.Local)},
+		{Merchant: "Merchant2", Amount: 25, Time: time.Date(2021, 10, 10, 9, 58, 30, 0, time.Local)},
+	})
+
+	ts := NewTransaction(accountRepoMock, authorizationRepo, lock.NewInMemoryLocker(), identifier.NewSequentialGenerator(), repository.NewInMemoryOutbox(clock.NewSystemClock()), domain.RetentionPolicy{MaxAge: time.Minute})
+
+	decision, err := ts.Authorize(domain.Transaction{
+		Merchant: "Merchant3",
+		Amount:   25,
+		Time:     time.Date(2021, 10, 10, 10, 0, 0, 0, time.Local),
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, decision.Violations)
+
+	page, _ := authorizationRepo.List(ports.AuthorizationQuery{})
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "Merchant2", page.Authorizations[0].Merchant)
+	assert.Equal(t, "Merchant3", page.Authorizations[1].Merchant)
+}
+
+func TestTransaction_Authorize_Prunes_History_Keeping_Lateness(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	_, _, _ = NewAccount(accountRepo, locker, clock.NewSystemClock()).InitAccount(true, 1000)
+
+	ts := NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{MaxAge: time.Minute}).
+		WithOrdering(domain.OrderingPolicy{Lateness: time.Minute})
+
+	_, _ = ts.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 20, Time: time.Date(2019, 2, 13, 11, 0, 0, 0, time.UTC)})
+	_, _ = ts.Authorize(domain.Transaction{Merchant: "Habbib's", Amount: 20, Time: time.Date(2019, 2, 13, 11, 2, 30, 0, time.UTC)})
+
+	decision, err := ts.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 20, Time: time.Date(2019, 2, 13, 11, 1, 45, 0, time.UTC)})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Violations{domain.DoubledTransactionViolation}, decision.Violations)
+}
+
+func seedAuthorizations(authorizations []domain.TransactionAuthorization) *repository.AuthorizationRepository {
+	authorizationRepo := repository.NewAuthorizationRepository()
+
+	for _, authorization := range authorizations {
+		_ = authorizationRepo.Save(authorization)
+	}
+
+	return authorizationRepo
 }
@@ -0,0 +1,22 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	c := NewFakeClock(baseTime)
+
+	assert.Equal(t, baseTime, c.Now())
+
+	c.Advance(time.Minute)
+	assert.Equal(t, baseTime.Add(time.Minute), c.Now())
+
+	c.Set(baseTime.Add(-time.Hour))
+	assert.Equal(t, baseTime.Add(-time.Hour), c.Now())
+}
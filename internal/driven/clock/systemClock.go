@@ -0,0 +1,16 @@
+package clock
+
+import "time"
+
+// SystemClock is the wall clock of the machine
+type SystemClock struct{}
+
+// NewSystemClock create a new SystemClock instance
+func NewSystemClock() SystemClock {
+	return SystemClock{}
+}
+
+// Now return the current time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
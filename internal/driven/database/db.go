@@ -4,4 +4,16 @@ type DB interface {
 	Insert(tableName string, id int64, data interface{}) error
 	Find(tableName string, id int64, target interface{}) error
 	Update(tableName string, id int64, data interface{}) error
+	Delete(tableName string, id int64) error
+	Scan(tableName string, fn func(id int64, decode func(target interface{}) error) error) error
+	NextID(tableName string) (int64, error)
+	Batch(writes ...Write) error
+}
+
+// Write is one insert or update applied by Batch
+type Write struct {
+	Table  string
+	ID     int64
+	Data   interface{}
+	Insert bool
 }
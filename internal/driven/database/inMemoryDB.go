@@ -4,22 +4,36 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 )
 
-var ErrNoRecords = errors.New("no records found")
+var (
+	ErrNoRecords     = errors.New("no records found")
+	ErrAlreadyExists = errors.New("record already exists")
+)
 
+// InMemoryDB is a DB kept in memory, safe for concurrent use
 type InMemoryDB struct {
-	storage map[string]map[int64][]byte
+	mu        sync.RWMutex
+	storage   map[string]map[int64][]byte
+	sequences map[string]int64
 }
 
-func NewInMemoryDB() InMemoryDB {
-	return InMemoryDB{storage: make(map[string]map[int64][]byte)}
+func NewInMemoryDB() *InMemoryDB {
+	return &InMemoryDB{storage: make(map[string]map[int64][]byte), sequences: make(map[string]int64)}
 }
 
-func (db InMemoryDB) Insert(tableName string, id int64, data interface{}) error {
-	table, existsTable := db.storage[tableName]
+func (db *InMemoryDB) Insert(tableName string, id int64, data interface{}) error {
+	j, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding data for id '%d': %w", id, err)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-	j, _ := json.Marshal(data)
+	table, existsTable := db.storage[tableName]
 
 	if !existsTable {
 		db.storage[tableName] = make(map[int64][]byte)
@@ -28,7 +42,7 @@ func (db InMemoryDB) Insert(tableName string, id int64, data interface{}) error
 	}
 
 	if _, exists := table[id]; exists {
-		return fmt.Errorf("there is already a data for id '%d'", id)
+		return fmt.Errorf("%w: there is already a data for id '%d'", ErrAlreadyExists, id)
 	}
 
 	table[id] = j
@@ -36,27 +50,151 @@ func (db InMemoryDB) Insert(tableName string, id int64, data interface{}) error
 	return nil
 }
 
-func (db InMemoryDB) Find(tableName string, id int64, target interface{}) error {
+func (db *InMemoryDB) Find(tableName string, id int64, target interface{}) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
 	table, existsTable := db.storage[tableName]
 
 	if !existsTable {
 		return ErrNoRecords
 	}
 
-	if v, exists := table[id]; exists {
-		_ = json.Unmarshal(v, target)
-		return nil
+	v, exists := table[id]
+	if !exists {
+		return ErrNoRecords
 	}
 
-	return ErrNoRecords
+	if err := json.Unmarshal(v, target); err != nil {
+		return fmt.Errorf("decoding data for id '%d': %w", id, err)
+	}
+
+	return nil
 }
 
-func (db InMemoryDB) Update(tableName string, id int64, data interface{}) error {
-	table := db.storage[tableName]
+func (db *InMemoryDB) Update(tableName string, id int64, data interface{}) error {
+	j, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encoding data for id '%d': %w", id, err)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
-	j, _ := json.Marshal(data)
+	table, existsTable := db.storage[tableName]
+
+	if !existsTable {
+		return ErrNoRecords
+	}
+
+	if _, exists := table[id]; !exists {
+		return ErrNoRecords
+	}
 
 	table[id] = j
 
 	return nil
 }
+
+func (db *InMemoryDB) Delete(tableName string, id int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, exists := db.storage[tableName][id]; !exists {
+		return ErrNoRecords
+	}
+
+	delete(db.storage[tableName], id)
+
+	return nil
+}
+
+// Scan hand every record of the table to fn in ID order, the records are read before
+// the first call so fn may write to the DB
+func (db *InMemoryDB) Scan(tableName string, fn func(id int64, decode func(target interface{}) error) error) error {
+	db.mu.RLock()
+	ids := make([]int64, 0, len(db.storage[tableName]))
+	records := make(map[int64][]byte, len(db.storage[tableName]))
+
+	for id, v := range db.storage[tableName] {
+		ids = append(ids, id)
+		records[id] = v
+	}
+	db.mu.RUnlock()
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		v := records[id]
+
+		decode := func(target interface{}) error {
+			if err := json.Unmarshal(v, target); err != nil {
+				return fmt.Errorf("decoding data for id '%d': %w", id, err)
+			}
+			return nil
+		}
+
+		if err := fn(id, decode); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NextID return a new ID for the table, IDs grow and are never handed out twice
+func (db *InMemoryDB) NextID(tableName string) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.sequences[tableName]++
+
+	return db.sequences[tableName], nil
+}
+
+// Batch apply the writes in order as a single change, none is applied when one of them fails
+func (db *InMemoryDB) Batch(writes ...Write) error {
+	encoded := make([][]byte, 0, len(writes))
+
+	for _, w := range writes {
+		j, err := json.Marshal(w.Data)
+		if err != nil {
+			return fmt.Errorf("encoding data for id '%d': %w", w.ID, err)
+		}
+
+		encoded = append(encoded, j)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	staged := make(map[string]map[int64]bool)
+
+	for _, w := range writes {
+		_, exists := db.storage[w.Table][w.ID]
+		exists = exists || staged[w.Table][w.ID]
+
+		if w.Insert && exists {
+			return fmt.Errorf("%w: there is already a data for id '%d'", ErrAlreadyExists, w.ID)
+		}
+
+		if !w.Insert && !exists {
+			return ErrNoRecords
+		}
+
+		if staged[w.Table] == nil {
+			staged[w.Table] = make(map[int64]bool)
+		}
+		staged[w.Table][w.ID] = true
+	}
+
+	for i, w := range writes {
+		if db.storage[w.Table] == nil {
+			db.storage[w.Table] = make(map[int64][]byte)
+		}
+
+		db.storage[w.Table][w.ID] = encoded[i]
+	}
+
+	return nil
+}
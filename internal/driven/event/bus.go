@@ -0,0 +1,67 @@
+package event
+
+import (
+	"sync"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// Handler reacts to a published event
+type Handler func(event domain.Event) error
+
+// Bus delivers events in process, synchronously and in the order they were published,
+// to the handlers subscribed to their type and to those subscribed to every type
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	all      []Handler
+}
+
+// NewBus create a new Bus instance
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe register the handler for one event type
+func (b *Bus) Subscribe(eventType string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers[eventType] = append(b.handlers[eventType], h)
+}
+
+// SubscribeAll register the handler for every event type
+func (b *Bus) SubscribeAll(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.all = append(b.all, h)
+}
+
+// Publish hand every event to its handlers, a failing handler does not stop the others
+// and the first error is returned once all of them ran. Handlers run without the bus lock,
+// so they may subscribe and a slow one does not hold up subscriptions
+func (b *Bus) Publish(events ...domain.Event) error {
+	var first error
+
+	for _, event := range events {
+		for _, h := range b.subscribed(event.EventType()) {
+			if err := h(event); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
+
+// subscribed return a copy of the handlers of the event type followed by those of every type
+func (b *Bus) subscribed(eventType string) []Handler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	handlers := make([]Handler, 0, len(b.handlers[eventType])+len(b.all))
+	handlers = append(handlers, b.handlers[eventType]...)
+
+	return append(handlers, b.all...)
+}
@@ -0,0 +1,97 @@
+package event
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Publish(t *testing.T) {
+	eventTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	approved := domain.TransactionApproved{AuthorizationID: "1", Time: eventTime}
+	declined := domain.TransactionDeclined{AuthorizationID: "2", Time: eventTime}
+
+	handlerErr := errors.New("handler failed")
+
+	testCases := []struct {
+		name          string
+		failing       bool
+		expectedTyped []string
+		expectedAll   []string
+		expectedErr   error
+	}{
+		{
+			name:          "entrega para os assinantes do tipo e de todos os tipos",
+			expectedTyped: []string{"1"},
+			expectedAll:   []string{"1", "2"},
+		},
+		{
+			name:          "erro de um assinante não impede os demais",
+			failing:       true,
+			expectedTyped: []string{"1"},
+			expectedAll:   []string{"1", "2"},
+			expectedErr:   handlerErr,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := NewBus()
+
+			var typed, all []string
+
+			bus.Subscribe(domain.TransactionApprovedEvent, func(e domain.Event) error {
+				typed = append(typed, e.(domain.TransactionApproved).AuthorizationID)
+				if tt.failing {
+					return handlerErr
+				}
+				return nil
+			})
+
+			bus.SubscribeAll(func(e domain.Event) error {
+				switch event := e.(type) {
+				case domain.TransactionApproved:
+					all = append(all, event.AuthorizationID)
+				case domain.TransactionDeclined:
+					all = append(all, event.AuthorizationID)
+				}
+				return nil
+			})
+
+			err := bus.Publish(approved, declined)
+
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedTyped, typed)
+			assert.Equal(t, tt.expectedAll, all)
+		})
+	}
+}
+
+func TestBus_Publish_Handler_Subscribes(t *testing.T) {
+	eventTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	bus := NewBus()
+
+	var late []string
+
+	bus.Subscribe(domain.TransactionApprovedEvent, func(domain.Event) error {
+		// subscribing from a handler must not wait on the publish in progress
+		bus.SubscribeAll(func(e domain.Event) error {
+			late = append(late, e.(domain.TransactionDeclined).AuthorizationID)
+			return nil
+		})
+		return nil
+	})
+
+	err := bus.Publish(
+		domain.TransactionApproved{AuthorizationID: "1", Time: eventTime},
+		domain.TransactionDeclined{AuthorizationID: "2", Time: eventTime},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2"}, late, "the new handler gets the events published after it subscribed")
+}
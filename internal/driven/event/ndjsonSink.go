@@ -0,0 +1,46 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/dto"
+)
+
+// NDJSONSink writes every event as one JSON line
+type NDJSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewNDJSONSink create a new NDJSONSink instance
+func NewNDJSONSink(w io.Writer) *NDJSONSink {
+	return &NDJSONSink{w: w}
+}
+
+// Publish write the events in order, each with a single write
+func (s *NDJSONSink) Publish(events ...domain.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		jm, err := json.Marshal(dto.NewEvent(event))
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.w.Write(append(jm, '\n')); err != nil {
+			return fmt.Errorf("writing %s event: %w", event.EventType(), err)
+		}
+	}
+
+	return nil
+}
+
+// Handle write a single event, so the sink can be subscribed to a Bus
+func (s *NDJSONSink) Handle(event domain.Event) error {
+	return s.Publish(event)
+}
@@ -0,0 +1,43 @@
+package event
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNDJSONSink_Publish(t *testing.T) {
+	var buf bytes.Buffer
+
+	sink := NewNDJSONSink(&buf)
+	bus := NewBus()
+	bus.SubscribeAll(sink.Handle)
+
+	eventTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	err := bus.Publish(
+		domain.RuleTriggered{
+			AccountID:       1,
+			AuthorizationID: "00000000000000000001",
+			Rule:            "max transactions in 2 minutes",
+			Violation:       "high-frequency-small-interval",
+			Time:            eventTime,
+		},
+		domain.TransactionDeclined{
+			AccountID:       1,
+			AuthorizationID: "00000000000000000001",
+			Merchant:        "Vivara",
+			Amount:          20,
+			AvailableLimit:  10,
+			Violations:      domain.Violations{"high-frequency-small-interval"},
+			Time:            eventTime,
+		},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "{\"type\":\"RuleTriggered\",\"account-id\":1,\"time\":\"2021-10-10T10:00:00Z\",\"authorization-id\":\"00000000000000000001\",\"rule\":\"max transactions in 2 minutes\",\"violation\":\"high-frequency-small-interval\"}\n"+
+		"{\"type\":\"TransactionDeclined\",\"account-id\":1,\"time\":\"2021-10-10T10:00:00Z\",\"authorization-id\":\"00000000000000000001\",\"merchant\":\"Vivara\",\"amount\":20,\"available-limit\":10,\"violations\":[\"high-frequency-small-interval\"]}\n", buf.String())
+}
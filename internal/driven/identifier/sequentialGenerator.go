@@ -0,0 +1,29 @@
+package identifier
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SequentialGenerator creates zero padded sequence numbers, sortable and predictable
+// for tests and reproducible runs
+type SequentialGenerator struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewSequentialGenerator create a new SequentialGenerator instance starting at 1
+func NewSequentialGenerator() *SequentialGenerator {
+	return &SequentialGenerator{next: 1}
+}
+
+// NewID return the next number of the sequence
+func (g *SequentialGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := fmt.Sprintf("%020d", g.next)
+	g.next++
+
+	return id
+}
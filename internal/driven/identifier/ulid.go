@@ -0,0 +1,91 @@
+package identifier
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/ports"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULIDGenerator creates ULIDs: 48 bits of milliseconds followed by 80 random bits,
+// encoded as 26 Crockford base32 characters. IDs created within the same millisecond
+// increment the random part so they keep sorting in creation order
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	clock   ports.Clock
+	entropy io.Reader
+	lastMs  uint64
+	last    [16]byte
+}
+
+// NewULIDGenerator create a new ULIDGenerator instance stamping IDs on the clock
+func NewULIDGenerator(c ports.Clock) *ULIDGenerator {
+	return &ULIDGenerator{clock: c, entropy: rand.Reader}
+}
+
+// NewID return the next ULID, it panics only when the system entropy source fails
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.clock.Now().UnixNano() / int64(time.Millisecond))
+
+	if ms <= g.lastMs {
+		// same millisecond or the clock went back: keep the last timestamp and bump the
+		// random part, moving to the next millisecond when it overflows
+		ms = g.lastMs
+
+		if increment(g.last[6:]) {
+			ms++
+		}
+	} else if _, err := io.ReadFull(g.entropy, g.last[6:]); err != nil {
+		panic("identifier: reading entropy: " + err.Error())
+	}
+
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], ms)
+	copy(g.last[:6], timestamp[2:])
+
+	g.lastMs = ms
+
+	return encode(g.last)
+}
+
+// increment adds one to the big-endian number and tells if it overflowed
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+
+		if b[i] != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// encode writes the 128 bits as 26 characters of 5 bits, the first one holding only 3
+func encode(id [16]byte) string {
+	out := make([]byte, 26)
+
+	for i := range out {
+		var value byte
+
+		for bit := i*5 - 2; bit < i*5+3; bit++ {
+			value <<= 1
+
+			if bit >= 0 && id[bit/8]&(0x80>>uint(bit%8)) != 0 {
+				value |= 1
+			}
+		}
+
+		out[i] = crockford[value]
+	}
+
+	return string(out)
+}
@@ -0,0 +1,55 @@
+package identifier
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestULIDGenerator_NewID(t *testing.T) {
+	fakeClock := clock.NewFakeClock(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+
+	g := &ULIDGenerator{
+		clock:   fakeClock,
+		entropy: bytes.NewReader(bytes.Repeat([]byte{0xFF}, 10)),
+	}
+
+	first := g.NewID()
+	second := g.NewID()
+
+	// 2021-10-10T10:00:00Z is 1633860000000 ms, 0x017C69A51900 in hexadecimal
+	assert.Equal(t, "01FHMTA680ZZZZZZZZZZZZZZZZ", first)
+	assert.Equal(t, "01FHMTA6810000000000000000", second, "overflowing the random part moves to the next millisecond")
+
+	fakeClock.Set(time.Date(2021, 10, 10, 9, 59, 59, 0, time.UTC))
+
+	assert.True(t, g.NewID() > second, "a clock going back keeps the order")
+}
+
+func TestULIDGenerator_NewID_Sortable(t *testing.T) {
+	g := NewULIDGenerator(clock.NewSystemClock())
+
+	ids := make([]string, 1000)
+	seen := make(map[string]bool)
+
+	for i := range ids {
+		ids[i] = g.NewID()
+		seen[ids[i]] = true
+
+		assert.Len(t, ids[i], 26)
+	}
+
+	assert.Len(t, seen, len(ids))
+	assert.True(t, sort.StringsAreSorted(ids))
+}
+
+func TestSequentialGenerator_NewID(t *testing.T) {
+	g := NewSequentialGenerator()
+
+	assert.Equal(t, "00000000000000000001", g.NewID())
+	assert.Equal(t, "00000000000000000002", g.NewID())
+}
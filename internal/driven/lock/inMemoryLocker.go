@@ -0,0 +1,44 @@
+package lock
+
+import "sync"
+
+type entry struct {
+	mu      sync.Mutex
+	holders int
+}
+
+// InMemoryLocker keeps one mutex per account, released when nobody holds or waits for it
+type InMemoryLocker struct {
+	mu      sync.Mutex
+	entries map[int64]*entry
+}
+
+// NewInMemoryLocker create a new InMemoryLocker instance
+func NewInMemoryLocker() *InMemoryLocker {
+	return &InMemoryLocker{entries: make(map[int64]*entry)}
+}
+
+// Lock blocks until the account is free and returns the function that releases it
+func (l *InMemoryLocker) Lock(accountID int64) func() {
+	l.mu.Lock()
+	e, exists := l.entries[accountID]
+	if !exists {
+		e = &entry{}
+		l.entries[accountID] = e
+	}
+	e.holders++
+	l.mu.Unlock()
+
+	e.mu.Lock()
+
+	return func() {
+		e.mu.Unlock()
+
+		l.mu.Lock()
+		e.holders--
+		if e.holders == 0 {
+			delete(l.entries, accountID)
+		}
+		l.mu.Unlock()
+	}
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/dto"
+)
+
+// Decisions as written in the decision log
+const (
+	ApprovedDecision = "approved"
+	DeclinedDecision = "declined"
+	ErrorDecision    = "error"
+)
+
+// DecisionLog writes a record for every authorization: approved ones at info, declined ones
+// at warn and the ones that failed at error. Approved records can be sampled
+type DecisionLog struct {
+	logger  *Logger
+	sampler *sampler
+}
+
+// NewDecisionLog create a new DecisionLog instance keeping sampleApproved of the approved
+// records, from 0 for none to 1 for every one
+func NewDecisionLog(l *Logger, sampleApproved float64) DecisionLog {
+	return DecisionLog{logger: l, sampler: &sampler{rate: math.Max(0, math.Min(1, sampleApproved))}}
+}
+
+// ObserveAuthorization write the record of the authorization
+func (dl DecisionLog) ObserveAuthorization(
+	transaction domain.Transaction,
+	decision domain.Decision,
+	err error,
+	latency time.Duration,
+) {
+	level, outcome := InfoLevel, ApprovedDecision
+
+	switch {
+	case err != nil:
+		level, outcome = ErrorLevel, ErrorDecision
+	case len(decision.Violations) > 0:
+		level, outcome = WarnLevel, DeclinedDecision
+	}
+
+	if !dl.logger.Enabled(level) || (outcome == ApprovedDecision && !dl.sampler.keep()) {
+		return
+	}
+
+	violations := decision.Violations
+	if violations == nil {
+		violations = domain.Violations{}
+	}
+
+	fields := []Field{
+		{Key: "account", Value: domain.DefaultAccountID},
+		{Key: "authorization-id", Value: decision.AuthorizationID},
+		{Key: "merchant", Value: transaction.Merchant},
+		{Key: "amount", Value: transaction.Amount},
+		{Key: "transaction-time", Value: transaction.Time},
+		{Key: "decision", Value: outcome},
+		{Key: "violations", Value: violations},
+		{Key: "rules", Value: dto.NewAccountViewOutput(decision.Account, nil).Account.Rules},
+		{Key: "latency-seconds", Value: latency.Seconds()},
+	}
+
+	if err != nil {
+		fields = append(fields, Field{Key: "error", Value: err})
+	}
+
+	dl.logger.Log(level, "authorization", fields...)
+}
+
+// sampler keeps a steady share of the records it is asked about, without randomness so
+// runs over the same input log the same records
+type sampler struct {
+	mu   sync.Mutex
+	rate float64
+	seen uint64
+}
+
+func (s *sampler) keep() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seen++
+
+	return math.Floor(float64(s.seen)*s.rate) > math.Floor(float64(s.seen-1)*s.rate)
+}
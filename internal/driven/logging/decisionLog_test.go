@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var record map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestDecisionLog_ObserveAuthorization(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+	transaction := domain.Transaction{Merchant: "Burger King", Amount: 20, Time: baseTime}
+	account := &domain.Account{
+		Ledger:          domain.Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 80},
+		SpendingControl: domain.SpendingControl{Rules: domain.DefaultRules()},
+	}
+
+	var buf bytes.Buffer
+
+	dl := NewDecisionLog(NewLogger(&buf, InfoLevel, clock.NewFakeClock(baseTime)), 1)
+
+	dl.ObserveAuthorization(transaction, domain.Decision{AuthorizationID: "1", Account: account, Violations: domain.Violations{}}, nil, 2*time.Millisecond)
+	dl.ObserveAuthorization(transaction, domain.Decision{AuthorizationID: "2", Account: account, Violations: domain.Violations{domain.DoubledTransactionViolation}}, nil, time.Millisecond)
+	dl.ObserveAuthorization(transaction, domain.Decision{}, errors.New("disk full"), time.Millisecond)
+
+	records := decodeRecords(t, &buf)
+
+	assert.Len(t, records, 3)
+
+	approved := records[0]
+	assert.Equal(t, "info", approved["level"])
+	assert.Equal(t, "authorization", approved["msg"])
+	assert.Equal(t, "approved", approved["decision"])
+	assert.Equal(t, "1", approved["authorization-id"])
+	assert.Equal(t, "Burger King", approved["merchant"])
+	assert.Equal(t, float64(20), approved["amount"])
+	assert.Equal(t, float64(1), approved["account"])
+	assert.Equal(t, "2021-10-10T10:00:00Z", approved["transaction-time"])
+	assert.Equal(t, []interface{}{}, approved["violations"])
+	assert.Equal(t, 0.002, approved["latency-seconds"])
+	assert.Len(t, approved["rules"], 1)
+
+	declined := records[1]
+	assert.Equal(t, "warn", declined["level"])
+	assert.Equal(t, "declined", declined["decision"])
+	assert.Equal(t, []interface{}{"doubled-transaction"}, declined["violations"])
+
+	failed := records[2]
+	assert.Equal(t, "error", failed["level"])
+	assert.Equal(t, "error", failed["decision"])
+	assert.Equal(t, "disk full", failed["error"])
+}
+
+func TestDecisionLog_Levels_And_Sampling(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+	approved := domain.Decision{Violations: domain.Violations{}}
+	declined := domain.Decision{Violations: domain.Violations{domain.InsufficientLimitViolation}}
+
+	testCases := []struct {
+		name             string
+		level            Level
+		sampleApproved   float64
+		expectedApproved int
+		expectedDeclined int
+	}{
+		{name: "registrando todas as decisões", level: InfoLevel, sampleApproved: 1, expectedApproved: 10, expectedDeclined: 10},
+		{name: "amostrando as aprovações", level: InfoLevel, sampleApproved: 0.25, expectedApproved: 2, expectedDeclined: 10},
+		{name: "sem aprovações", level: InfoLevel, sampleApproved: 0, expectedApproved: 0, expectedDeclined: 10},
+		{name: "nível warn descarta as aprovações", level: WarnLevel, sampleApproved: 1, expectedApproved: 0, expectedDeclined: 10},
+		{name: "nível error descarta as recusas", level: ErrorLevel, sampleApproved: 1, expectedApproved: 0, expectedDeclined: 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			dl := NewDecisionLog(NewLogger(&buf, tt.level, clock.NewFakeClock(baseTime)), tt.sampleApproved)
+
+			for i := 0; i < 10; i++ {
+				dl.ObserveAuthorization(domain.Transaction{}, approved, nil, 0)
+				dl.ObserveAuthorization(domain.Transaction{}, declined, nil, 0)
+			}
+
+			counts := map[string]int{}
+			for _, record := range decodeRecords(t, &buf) {
+				counts[record["decision"].(string)]++
+			}
+
+			assert.Equal(t, tt.expectedApproved, counts["approved"])
+			assert.Equal(t, tt.expectedDeclined, counts["declined"])
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/authorizer/internal/core/ports"
+)
+
+// Level is the severity of a record, records below the logger level are dropped
+type Level int
+
+// Levels from the least to the most severe
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+func (l Level) String() string {
+	if l < DebugLevel || l > ErrorLevel {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+
+	return levelNames[l]
+}
+
+// ParseLevel return the level with the given name, debug, info, warn or error
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+
+	return DebugLevel, fmt.Errorf("unknown log level %q", name)
+}
+
+// Field is a key and value added to a record, in the order given
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger writes one JSON record per line with its time, level and message followed by the
+// fields, it is safe for concurrent use
+type Logger struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level Level
+	clock ports.Clock
+}
+
+// NewLogger create a new Logger instance writing the records at level or above to w, stamped
+// on the clock
+func NewLogger(w io.Writer, level Level, c ports.Clock) *Logger {
+	return &Logger{mu: &sync.Mutex{}, w: w, level: level, clock: c}
+}
+
+// Enabled tells if records at level are written, a nil Logger writes none
+func (l *Logger) Enabled(level Level) bool {
+	return l != nil && level >= l.level
+}
+
+// Log write the record when its level is enabled
+func (l *Logger) Log(level Level, msg string, fields ...Field) {
+	if !l.Enabled(level) {
+		return
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	writeField(&buf, "time", l.clock.Now())
+	buf.WriteByte(',')
+	writeField(&buf, "level", level.String())
+	buf.WriteByte(',')
+	writeField(&buf, "msg", msg)
+
+	for _, field := range fields {
+		buf.WriteByte(',')
+		writeField(&buf, field.Key, field.Value)
+	}
+
+	buf.WriteString("}\n")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, _ = l.w.Write(buf.Bytes())
+}
+
+// Debug write a debug record
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.Log(DebugLevel, msg, fields...)
+}
+
+// Info write an info record
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.Log(InfoLevel, msg, fields...)
+}
+
+// Warn write a warn record
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.Log(WarnLevel, msg, fields...)
+}
+
+// Error write an error record
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.Log(ErrorLevel, msg, fields...)
+}
+
+// Writer return a writer turning every write into a record at level, so a log.Logger of the
+// standard library, such as the error log of an http.Server, can write to the logger
+func (l *Logger) Writer(level Level) io.Writer {
+	return levelWriter{logger: l, level: level}
+}
+
+type levelWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w levelWriter) Write(p []byte) (int, error) {
+	w.logger.Log(w.level, strings.TrimSpace(string(p)))
+	return len(p), nil
+}
+
+func writeField(buf *bytes.Buffer, key string, value interface{}) {
+	jk, _ := json.Marshal(key)
+
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+
+	jv, err := json.Marshal(value)
+	if err != nil {
+		jv, _ = json.Marshal(fmt.Sprint(value))
+	}
+
+	buf.Write(jk)
+	buf.WriteByte(':')
+	buf.Write(jv)
+}
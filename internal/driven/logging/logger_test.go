@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_Log(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := NewLogger(&buf, InfoLevel, clock.NewFakeClock(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)))
+
+	logger.Debug("ignorado")
+	logger.Info("listening", Field{Key: "addr", Value: ":9000"})
+	logger.Error("relay", Field{Key: "error", Value: errors.New("broker down")})
+
+	assert.Equal(t, ""+
+		`{"time":"2021-10-10T10:00:00Z","level":"info","msg":"listening","addr":":9000"}`+"\n"+
+		`{"time":"2021-10-10T10:00:00Z","level":"error","msg":"relay","error":"broker down"}`+"\n",
+		buf.String())
+}
+
+func TestLogger_Log_Nil(t *testing.T) {
+	var logger *Logger
+
+	assert.False(t, logger.Enabled(ErrorLevel))
+	assert.NotPanics(t, func() { logger.Error("descartado") })
+}
+
+func TestLogger_Writer(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := NewLogger(&buf, InfoLevel, clock.NewFakeClock(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)))
+
+	std := log.New(logger.Writer(WarnLevel), "", 0)
+	std.Printf("connection %s: %v", "127.0.0.1:5000", "reset")
+
+	assert.Equal(t, `{"time":"2021-10-10T10:00:00Z","level":"warn","msg":"connection 127.0.0.1:5000: reset"}`+"\n", buf.String())
+}
+
+func TestParseLevel(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         string
+		expectedLevel Level
+		expectedErr   bool
+	}{
+		{name: "nível debug", level: "debug", expectedLevel: DebugLevel},
+		{name: "nível em maiúsculas", level: "WARN", expectedLevel: WarnLevel},
+		{name: "nível error", level: "error", expectedLevel: ErrorLevel},
+		{name: "nível desconhecido", level: "verbose", expectedErr: true},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			level, err := ParseLevel(tt.level)
+
+			assert.Equal(t, tt.expectedErr, err != nil)
+			if !tt.expectedErr {
+				assert.Equal(t, tt.expectedLevel, level)
+			}
+		})
+	}
+}
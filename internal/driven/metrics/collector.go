@@ -0,0 +1,203 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// counterVec is a family of counters told apart by the values of their labels
+type counterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]float64
+	keys   map[string][]string
+}
+
+func newCounterVec(name, help string, labels ...string) *counterVec {
+	return &counterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]float64),
+		keys:   make(map[string][]string),
+	}
+}
+
+// inc add one to the counter with the label values, given in the order of the labels
+func (c *counterVec) inc(labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.values[key]++
+	c.keys[key] = labelValues
+}
+
+// get return the counter with the label values
+func (c *counterVec) get(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.values[strings.Join(labelValues, "\xff")]
+}
+
+func (c *counterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(c.keys) {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, labelPairs(c.labels, c.keys[key]), formatFloat(c.values[key])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// histogram counts observations in cumulative buckets by their upper bound
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// histogramVec is a family of histograms told apart by the values of their labels
+type histogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu         sync.Mutex
+	histograms map[string]*histogram
+	keys       map[string][]string
+}
+
+func newHistogramVec(name, help string, buckets []float64, labels ...string) *histogramVec {
+	return &histogramVec{
+		name:       name,
+		help:       help,
+		labels:     labels,
+		buckets:    buckets,
+		histograms: make(map[string]*histogram),
+		keys:       make(map[string][]string),
+	}
+}
+
+// observe add the value to the histogram with the label values
+func (h *histogramVec) observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist, exists := h.histograms[key]
+	if !exists {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.histograms[key] = hist
+		h.keys[key] = labelValues
+	}
+
+	for i, bound := range h.buckets {
+		if value <= bound {
+			hist.counts[i]++
+		}
+	}
+
+	hist.count++
+	hist.sum += value
+}
+
+// count return how many values the histogram with the label values observed
+func (h *histogramVec) count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if hist, exists := h.histograms[strings.Join(labelValues, "\xff")]; exists {
+		return hist.count
+	}
+
+	return 0
+}
+
+func (h *histogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(h.keys) {
+		hist := h.histograms[key]
+		values := h.keys[key]
+
+		bucketLabels := append(append([]string(nil), h.labels...), "le")
+
+		for i, bound := range h.buckets {
+			labels := labelPairs(bucketLabels, append(append([]string(nil), values...), formatFloat(bound)))
+			if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, hist.counts[i]); err != nil {
+				return err
+			}
+		}
+
+		infLabels := labelPairs(bucketLabels, append(append([]string(nil), values...), "+Inf"))
+		if _, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, infLabels, hist.count); err != nil {
+			return err
+		}
+
+		labels := labelPairs(h.labels, values)
+		if _, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.name, labels, formatFloat(hist.sum), h.name, labels, hist.count); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// labelEscaper escape label values the way the text exposition format expects
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labelPairs format the labels the way the text exposition format expects, nothing when there are none
+func labelPairs(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(names))
+
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, labelEscaper.Replace(values[i])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys return the keys of the series, so they are written in a stable order
+func sortedKeys(series map[string][]string) []string {
+	keys := make([]string, 0, len(series))
+
+	for key := range series {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
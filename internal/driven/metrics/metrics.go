@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// Outcomes of an authorization as counted by authorizer_authorizations_total
+const (
+	ApprovedOutcome = "approved"
+	DeclinedOutcome = "declined"
+	ErrorOutcome    = "error"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the latency histograms
+var LatencyBuckets = []float64{0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1}
+
+// Metrics counts authorization decisions and measures authorization and repository latencies,
+// it is exposed in the Prometheus text exposition format and safe for concurrent use
+type Metrics struct {
+	authorizations        *counterVec
+	declines              *counterVec
+	ruleTriggers          *counterVec
+	authorizationDuration *histogramVec
+	repositoryDuration    *histogramVec
+}
+
+// NewMetrics create a new Metrics instance with every counter at zero
+func NewMetrics() *Metrics {
+	return &Metrics{
+		authorizations: newCounterVec(
+			"authorizer_authorizations_total",
+			"Authorizations by outcome, approved, declined or error.",
+			"outcome",
+		),
+		declines: newCounterVec(
+			"authorizer_declines_total",
+			"Declined authorizations by violation, a decline with several violations counts for each.",
+			"violation",
+		),
+		ruleTriggers: newCounterVec(
+			"authorizer_rule_triggers_total",
+			"Spending control rules that failed an authorization, by rule name.",
+			"rule",
+		),
+		authorizationDuration: newHistogramVec(
+			"authorizer_authorization_duration_seconds",
+			"How long authorizations took.",
+			LatencyBuckets,
+		),
+		repositoryDuration: newHistogramVec(
+			"authorizer_repository_duration_seconds",
+			"How long repository operations took, by repository and operation.",
+			LatencyBuckets,
+			"repository", "operation",
+		),
+	}
+}
+
+// ObserveAuthorization count the decision by outcome, violation and triggered rule, and record
+// its latency
+func (m *Metrics) ObserveAuthorization(_ domain.Transaction, decision domain.Decision, err error, latency time.Duration) {
+	m.authorizationDuration.observe(latency.Seconds())
+
+	switch {
+	case err != nil:
+		m.authorizations.inc(ErrorOutcome)
+	case len(decision.Violations) == 0:
+		m.authorizations.inc(ApprovedOutcome)
+	default:
+		m.authorizations.inc(DeclinedOutcome)
+
+		for _, violation := range decision.Violations {
+			m.declines.inc(violation)
+		}
+	}
+
+	if decision.Account == nil {
+		return
+	}
+
+	failed := make(map[string]bool)
+	for _, check := range decision.Checks {
+		if !check.Passed {
+			failed[check.Name] = true
+		}
+	}
+
+	for _, rule := range decision.Account.SpendingControl.Rules {
+		if failed[rule.Name] {
+			m.ruleTriggers.inc(rule.Name)
+		}
+	}
+}
+
+// ObserveRepository record the latency of an operation on a repository
+func (m *Metrics) ObserveRepository(repository, operation string, latency time.Duration) {
+	m.repositoryDuration.observe(latency.Seconds(), repository, operation)
+}
+
+// Authorizations return how many authorizations had the outcome
+func (m *Metrics) Authorizations(outcome string) int64 {
+	return int64(m.authorizations.get(outcome))
+}
+
+// Declines return how many declined authorizations had the violation
+func (m *Metrics) Declines(violation string) int64 {
+	return int64(m.declines.get(violation))
+}
+
+// RuleTriggers return how many authorizations the rule failed
+func (m *Metrics) RuleTriggers(rule string) int64 {
+	return int64(m.ruleTriggers.get(rule))
+}
+
+// RepositoryOperations return how many operations on the repository were measured
+func (m *Metrics) RepositoryOperations(repository, operation string) int64 {
+	return int64(m.repositoryDuration.count(repository, operation))
+}
+
+// WriteTo write every metric in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+
+	writers := []func(io.Writer) error{
+		m.authorizations.write,
+		m.declines.write,
+		m.ruleTriggers.write,
+		m.authorizationDuration.write,
+		m.repositoryDuration.write,
+	}
+
+	for _, write := range writers {
+		if err := write(&buf); err != nil {
+			return 0, err
+		}
+	}
+
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP answer the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_ObserveAuthorization(t *testing.T) {
+	account := &domain.Account{
+		SpendingControl: domain.SpendingControl{Rules: domain.DefaultRules()},
+	}
+
+	m := NewMetrics()
+
+	m.ObserveAuthorization(domain.Transaction{}, domain.Decision{Account: account, Violations: domain.Violations{}}, nil, time.Millisecond)
+	m.ObserveAuthorization(domain.Transaction{}, domain.Decision{
+		Account:    account,
+		Violations: domain.Violations{"high-frequency-small-interval", domain.DoubledTransactionViolation},
+		Checks: []domain.Check{
+			domain.NewCheck(domain.LedgerCheck, "", nil),
+			domain.NewCheck("max transactions in 2 minutes", "high-frequency-small-interval", nil),
+			domain.NewCheck(domain.DoubledTransactionCheck, domain.DoubledTransactionViolation, nil),
+		},
+	}, nil, 2*time.Millisecond)
+	m.ObserveAuthorization(domain.Transaction{}, domain.Decision{Violations: domain.Violations{domain.AccountNotInitializedViolation}}, nil, time.Millisecond)
+	m.ObserveAuthorization(domain.Transaction{}, domain.Decision{}, errors.New("disk full"), time.Second)
+
+	assert.Equal(t, int64(1), m.Authorizations(ApprovedOutcome))
+	assert.Equal(t, int64(2), m.Authorizations(DeclinedOutcome))
+	assert.Equal(t, int64(1), m.Authorizations(ErrorOutcome))
+	assert.Equal(t, int64(1), m.Declines("high-frequency-small-interval"))
+	assert.Equal(t, int64(1), m.Declines(domain.DoubledTransactionViolation))
+	assert.Equal(t, int64(1), m.Declines(domain.AccountNotInitializedViolation))
+	assert.Equal(t, int64(1), m.RuleTriggers("max transactions in 2 minutes"))
+}
+
+func TestMetrics_WriteTo(t *testing.T) {
+	m := NewMetrics()
+
+	m.ObserveAuthorization(domain.Transaction{}, domain.Decision{Violations: domain.Violations{domain.CardNotActiveViolation}}, nil, 300*time.Microsecond)
+	m.ObserveRepository(AccountRepositoryName, "retrieve", 50*time.Microsecond)
+
+	var buf bytes.Buffer
+
+	_, err := m.WriteTo(&buf)
+
+	assert.NoError(t, err)
+
+	expected := []string{
+		"# HELP authorizer_authorizations_total Authorizations by outcome, approved, declined or error.",
+		"# TYPE authorizer_authorizations_total counter",
+		`authorizer_authorizations_total{outcome="declined"} 1`,
+		"# TYPE authorizer_declines_total counter",
+		`authorizer_declines_total{violation="card-not-active"} 1`,
+		"# TYPE authorizer_rule_triggers_total counter",
+		"# TYPE authorizer_authorization_duration_seconds histogram",
+		`authorizer_authorization_duration_seconds_bucket{le="0.00025"} 0`,
+		`authorizer_authorization_duration_seconds_bucket{le="0.0005"} 1`,
+		`authorizer_authorization_duration_seconds_bucket{le="+Inf"} 1`,
+		"authorizer_authorization_duration_seconds_sum 0.0003",
+		"authorizer_authorization_duration_seconds_count 1",
+		`authorizer_repository_duration_seconds_bucket{repository="account",operation="retrieve",le="0.0001"} 1`,
+		`authorizer_repository_duration_seconds_count{repository="account",operation="retrieve"} 1`,
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+
+	for _, line := range expected {
+		assert.Contains(t, lines, line)
+	}
+}
+
+func TestMetrics_ServeHTTP(t *testing.T) {
+	m := NewMetrics()
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "# TYPE authorizer_authorizations_total counter")
+
+	rec = httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+// Repository names the latencies are recorded under
+const (
+	AccountRepositoryName       = "account"
+	AuthorizationRepositoryName = "authorization"
+)
+
+// AccountRepository records the latency of every operation on the wrapped ports.AccountRepository
+type AccountRepository struct {
+	repo    ports.AccountRepository
+	metrics *Metrics
+	clock   ports.Clock
+}
+
+// NewAccountRepository create a new AccountRepository instance measuring r on the clock
+func NewAccountRepository(r ports.AccountRepository, m *Metrics, c ports.Clock) AccountRepository {
+	return AccountRepository{repo: r, metrics: m, clock: c}
+}
+
+// Create store the account through the wrapped repository
+func (ar AccountRepository) Create(account domain.Account, events ...domain.Event) error {
+	defer ar.observe("create", ar.clock.Now())
+	return ar.repo.Create(account, events...)
+}
+
+// Retrieve read the account through the wrapped repository
+func (ar AccountRepository) Retrieve(currentTime time.Time) (*domain.Account, error) {
+	defer ar.observe("retrieve", ar.clock.Now())
+	return ar.repo.Retrieve(currentTime)
+}
+
+// Update store the account through the wrapped repository
+func (ar AccountRepository) Update(account domain.Account, events ...domain.Event) error {
+	defer ar.observe("update", ar.clock.Now())
+	return ar.repo.Update(account, events...)
+}
+
+func (ar AccountRepository) observe(operation string, start time.Time) {
+	ar.metrics.ObserveRepository(AccountRepositoryName, operation, ar.clock.Now().Sub(start))
+}
+
+// AuthorizationRepository records the latency of every operation on the wrapped
+// ports.AuthorizationRepository
+type AuthorizationRepository struct {
+	repo    ports.AuthorizationRepository
+	metrics *Metrics
+	clock   ports.Clock
+}
+
+// NewAuthorizationRepository create a new AuthorizationRepository instance measuring r on the clock
+func NewAuthorizationRepository(r ports.AuthorizationRepository, m *Metrics, c ports.Clock) AuthorizationRepository {
+	return AuthorizationRepository{repo: r, metrics: m, clock: c}
+}
+
+// Save store the authorization through the wrapped repository
+func (ar AuthorizationRepository) Save(authorization domain.TransactionAuthorization) error {
+	defer ar.observe("save", ar.clock.Now())
+	return ar.repo.Save(authorization)
+}
+
+// Find read the authorization through the wrapped repository
+func (ar AuthorizationRepository) Find(id string) (*domain.TransactionAuthorization, error) {
+	defer ar.observe("find", ar.clock.Now())
+	return ar.repo.Find(id)
+}
+
+// List read a page of the history through the wrapped repository
+func (ar AuthorizationRepository) List(query ports.AuthorizationQuery) (ports.AuthorizationPage, error) {
+	defer ar.observe("list", ar.clock.Now())
+	return ar.repo.List(query)
+}
+
+// FindNearest read the nearest approved authorization through the wrapped repository
+func (ar AuthorizationRepository) FindNearest(merchant string, amount int64, at time.Time) (*domain.TransactionAuthorization, error) {
+	defer ar.observe("find-nearest", ar.clock.Now())
+	return ar.repo.FindNearest(merchant, amount, at)
+}
+
+// LatestApproved read the latest approved authorization through the wrapped repository
+func (ar AuthorizationRepository) LatestApproved() (*domain.TransactionAuthorization, error) {
+	defer ar.observe("latest-approved", ar.clock.Now())
+	return ar.repo.LatestApproved()
+}
+
+// Prune drop old authorizations through the wrapped repository
+func (ar AuthorizationRepository) Prune(before time.Time) (int, error) {
+	defer ar.observe("prune", ar.clock.Now())
+	return ar.repo.Prune(before)
+}
+
+func (ar AuthorizationRepository) observe(operation string, start time.Time) {
+	ar.metrics.ObserveRepository(AuthorizationRepositoryName, operation, ar.clock.Now().Sub(start))
+}
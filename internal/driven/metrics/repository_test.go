@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositories_Observe_Every_Operation(t *testing.T) {
+	m := NewMetrics()
+	fakeClock := clock.NewFakeClock(time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC))
+
+	inMemoryRepo := repository.NewInMemoryAccountRepository(fakeClock)
+	accountRepo := NewAccountRepository(inMemoryRepo, m, fakeClock)
+	authorizationRepo := NewAuthorizationRepository(repository.NewAuthorizationRepository(), m, fakeClock)
+	locker := lock.NewInMemoryLocker()
+
+	_, _, _ = service.NewAccount(accountRepo, locker, fakeClock).InitAccount(true, 100)
+
+	ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), inMemoryRepo.Outbox(), domain.RetentionPolicy{}).
+		WithObservers(fakeClock, m)
+
+	_, err := ts.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 20, Time: fakeClock.Now()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), m.Authorizations(ApprovedOutcome))
+
+	for _, op := range []struct{ repository, operation string }{
+		{AccountRepositoryName, "create"},
+		{AccountRepositoryName, "update"},
+		{AuthorizationRepositoryName, "latest-approved"},
+		{AuthorizationRepositoryName, "find-nearest"},
+		{AuthorizationRepositoryName, "save"},
+	} {
+		assert.Equal(t, int64(1), m.RepositoryOperations(op.repository, op.operation), op.operation)
+	}
+
+	assert.Equal(t, int64(2), m.RepositoryOperations(AccountRepositoryName, "retrieve"))
+}
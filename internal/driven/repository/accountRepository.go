@@ -1,61 +1,150 @@
 package repository
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
 	"github.com/authorizer/internal/driven/database"
 	"github.com/authorizer/internal/dto"
-	"time"
 )
 
 // AccountRepository represents a repository to domain.Account
 type AccountRepository struct {
-	db database.DB
+	db      database.DB
+	history ports.AuthorizationRepository
+	clock   ports.Clock
+
+	// upgrading serializes the upgrades of records stored at older schema versions
+	upgrading *sync.Mutex
 }
 
-// NewAccountRepository create a new AccountRepository instance
-func NewAccountRepository(db database.DB) AccountRepository {
-	return AccountRepository{db: db}
+// NewAccountRepository create a new AccountRepository instance stamping outbox entries on the
+// clock, the authorizations stored inside accounts of older schema versions are moved to
+// history when they are read
+func NewAccountRepository(db database.DB, history ports.AuthorizationRepository, c ports.Clock) AccountRepository {
+	return AccountRepository{db: db, history: history, clock: c, upgrading: &sync.Mutex{}}
 }
 
-// Create insert new account on DB
-func (ar AccountRepository) Create(account domain.Account) error {
-	accDTO := buildDBEntity(account)
-	_ = ar.db.Insert("accounts", 1, accDTO)
-	return nil
+// Create insert new account on DB, along with its events in the outbox
+func (ar AccountRepository) Create(account domain.Account, events ...domain.Event) error {
+	err := ar.write(account, events, true)
+
+	if errors.Is(err, database.ErrAlreadyExists) {
+		return ports.ErrAccountAlreadyExists
+	}
+
+	return storageError("create", err)
 }
 
-// Update update account
-func (ar AccountRepository) Update(account domain.Account) error {
-	accDTO := buildDBEntity(account)
-	_ = ar.db.Update("accounts", 1, accDTO)
-	return nil
+// Update update account, along with its events in the outbox
+func (ar AccountRepository) Update(account domain.Account, events ...domain.Event) error {
+	err := ar.write(account, events, false)
+
+	if errors.Is(err, database.ErrNoRecords) {
+		return ports.ErrAccountNotFound
+	}
+
+	return storageError("update", err)
 }
 
 // Retrieve find account and return
 func (ar AccountRepository) Retrieve(currentTime time.Time) (*domain.Account, error) {
-	var accountDTO dto.Account
-	err := ar.db.Find("accounts", 1, &accountDTO)
+	var raw json.RawMessage
+	err := ar.db.Find("accounts", domain.DefaultAccountID, &raw)
+
+	if errors.Is(err, database.ErrNoRecords) {
+		return nil, ports.ErrAccountNotFound
+	}
 
 	if err != nil {
-		return nil, err
+		return nil, storageError("retrieve", err)
+	}
+
+	accountDTO, err := ar.upgrade(raw)
+	if err != nil {
+		return nil, storageError("retrieve", err)
 	}
 
 	return buildDomainAccount(currentTime, accountDTO), nil
 }
 
-func buildDBEntity(domainAccount domain.Account) dto.Account {
-	transactionAuthorizations := make([]dto.TransactionAuthorization, 0, len(domainAccount.Authorizations))
-	rules := make([]dto.Rule, 0, len(domainAccount.SpendingControl.Rules))
+// upgrade decode the stored record and, when it was stored at an older schema version, move
+// the authorizations it kept to the history and store it back at the current version so the
+// upgrade runs once. The record is read again under the upgrade lock and only stored back while
+// it is still at the older version, so a read racing an update never writes the stale record
+// over it. Authorizations already in the history are not saved twice
+func (ar AccountRepository) upgrade(raw json.RawMessage) (dto.Account, error) {
+	accountDTO, version, _, err := decodeAccount(raw)
+	if err != nil || version == currentSchemaVersion {
+		return accountDTO, err
+	}
 
-	for _, ta := range domainAccount.Authorizations {
-		transactionAuthorizations = append(transactionAuthorizations, dto.TransactionAuthorization{
-			Merchant:       ta.Merchant,
-			Amount:         ta.Amount,
-			AvailableLimit: ta.AvailableLimit,
-			Time:           ta.Time,
-		})
+	ar.upgrading.Lock()
+	defer ar.upgrading.Unlock()
+
+	if err := ar.db.Find("accounts", domain.DefaultAccountID, &raw); err != nil {
+		return accountDTO, err
+	}
+
+	accountDTO, version, migrated, err := decodeAccount(raw)
+	if err != nil || version == currentSchemaVersion {
+		return accountDTO, err
 	}
 
+	for _, authorization := range migrated {
+		_, err := ar.history.Find(authorization.ID)
+
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, ports.ErrAuthorizationNotFound) {
+			return accountDTO, fmt.Errorf("migrating authorization history: %w", err)
+		}
+
+		if err := ar.history.Save(authorization); err != nil {
+			return accountDTO, fmt.Errorf("migrating authorization history: %w", err)
+		}
+	}
+
+	err = ar.db.Batch(database.Write{Table: "accounts", ID: domain.DefaultAccountID, Data: accountDTO})
+
+	return accountDTO, err
+}
+
+// write store the account and the events in a single batch
+func (ar AccountRepository) write(account domain.Account, events []domain.Event, insert bool) error {
+	writes, err := outboxWrites(ar.db, ar.clock.Now(), events)
+	if err != nil {
+		return err
+	}
+
+	accountWrite := database.Write{
+		Table:  "accounts",
+		ID:     domain.DefaultAccountID,
+		Data:   buildDBEntity(account),
+		Insert: insert,
+	}
+
+	return ar.db.Batch(append([]database.Write{accountWrite}, writes...)...)
+}
+
+func storageError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &ports.StorageError{Op: op, Err: err}
+}
+
+func buildDBEntity(domainAccount domain.Account) dto.Account {
+	rules := make([]dto.Rule, 0, len(domainAccount.SpendingControl.Rules))
+
 	for _, rule := range domainAccount.SpendingControl.Rules {
 		rules = append(rules, dto.Rule{
 			Name:       rule.Name,
@@ -72,29 +161,19 @@ func buildDBEntity(domainAccount domain.Account) dto.Account {
 	}
 
 	return dto.Account{
+		SchemaVersion: currentSchemaVersion,
 		Ledger: dto.Ledger{
 			Active:         domainAccount.Ledger.ActiveCard,
 			MaxLimit:       domainAccount.Ledger.MaxLimit,
 			AvailableLimit: domainAccount.Ledger.AvailableLimit,
 		},
 		SpendingControl: dto.SpendingControl{Rules: rules},
-		Transactions:    transactionAuthorizations,
 	}
 }
 
 func buildDomainAccount(currentTime time.Time, accountDTO dto.Account) *domain.Account {
-	transactionAuthorizations := make([]domain.TransactionAuthorization, 0, len(accountDTO.Transactions))
 	rules := make([]domain.Rule, 0, len(accountDTO.SpendingControl.Rules))
 
-	for _, ta := range accountDTO.Transactions {
-		transactionAuthorizations = append(transactionAuthorizations, domain.TransactionAuthorization{
-			Merchant:       ta.Merchant,
-			Amount:         ta.Amount,
-			AvailableLimit: ta.AvailableLimit,
-			Time:           ta.Time,
-		})
-	}
-
 	for _, rule := range accountDTO.SpendingControl.Rules {
 		domainAccumulator := domain.BuildAccumulator(
 			currentTime,
@@ -120,6 +199,5 @@ func buildDomainAccount(currentTime time.Time, accountDTO dto.Account) *domain.A
 			AvailableLimit: accountDTO.Ledger.AvailableLimit,
 		},
 		SpendingControl: domain.SpendingControl{Rules: rules},
-		Authorizations:  transactionAuthorizations,
 	}
 }
@@ -36,17 +36,22 @@ func (m *MockAccountRepository) EXPECT() *MockAccountRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockAccountRepository) Create(account domain.Account) error {
+func (m *MockAccountRepository) Create(account domain.Account, events ...domain.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", account)
+	varargs := []interface{}{account}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockAccountRepositoryMockRecorder) Create(account interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) Create(account interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccountRepository)(nil).Create), account)
+	varargs := append([]interface{}{account}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAccountRepository)(nil).Create), varargs...)
 }
 
 // Retrieve mocks base method.
@@ -65,15 +70,20 @@ func (mr *MockAccountRepositoryMockRecorder) Retrieve(currentTime interface{}) *
 }
 
 // Update mocks base method.
-func (m *MockAccountRepository) Update(account domain.Account) error {
+func (m *MockAccountRepository) Update(account domain.Account, events ...domain.Event) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", account)
+	varargs := []interface{}{account}
+	for _, a := range events {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Update", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockAccountRepositoryMockRecorder) Update(account interface{}) *gomock.Call {
+func (mr *MockAccountRepositoryMockRecorder) Update(account interface{}, events ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAccountRepository)(nil).Update), account)
+	varargs := append([]interface{}{account}, events...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockAccountRepository)(nil).Update), varargs...)
 }
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/database"
+)
+
+func benchmarkRetrieveUpdate(b *testing.B, ar ports.AccountRepository) {
+	_ = ar.Create(buildAccount())
+	currentTime := time.Date(2021, 10, 10, 10, 1, 0, 0, time.UTC)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		account, _ := ar.Retrieve(currentTime)
+		account.Ledger.AvailableLimit--
+		_ = ar.Update(*account)
+	}
+}
+
+func BenchmarkAccountRepository_RetrieveUpdate(b *testing.B) {
+	benchmarkRetrieveUpdate(b, NewAccountRepository(database.NewInMemoryDB(), NewAuthorizationRepository(), clock.NewSystemClock()))
+}
+
+func BenchmarkInMemoryAccountRepository_RetrieveUpdate(b *testing.B) {
+	benchmarkRetrieveUpdate(b, NewInMemoryAccountRepository(clock.NewSystemClock()))
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+type duplicateKey struct {
+	merchant string
+	amount   int64
+}
+
+// AuthorizationRepository keeps the authorization history in memory ordered by time,
+// with an index of the approved ones by merchant and amount to serve the doubled-transaction
+// check and another by ID to serve lookups
+type AuthorizationRepository struct {
+	mu             sync.RWMutex
+	authorizations []domain.TransactionAuthorization
+	index          map[duplicateKey][]domain.TransactionAuthorization
+	byID           map[string]domain.TransactionAuthorization
+}
+
+// NewAuthorizationRepository create a new AuthorizationRepository instance
+func NewAuthorizationRepository() *AuthorizationRepository {
+	return &AuthorizationRepository{
+		index: make(map[duplicateKey][]domain.TransactionAuthorization),
+		byID:  make(map[string]domain.TransactionAuthorization),
+	}
+}
+
+// Save insert the authorization keeping the history ordered by time
+func (ar *AuthorizationRepository) Save(authorization domain.TransactionAuthorization) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.authorizations = insertOrdered(ar.authorizations, authorization)
+
+	if authorization.ID != "" {
+		ar.byID[authorization.ID] = authorization
+	}
+
+	if authorization.Approved() {
+		key := duplicateKey{merchant: authorization.Merchant, amount: authorization.Amount}
+		ar.index[key] = insertOrdered(ar.index[key], authorization)
+	}
+
+	return nil
+}
+
+// Find return the authorization with the given ID
+func (ar *AuthorizationRepository) Find(id string) (*domain.TransactionAuthorization, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	authorization, exists := ar.byID[id]
+
+	if !exists {
+		return nil, ports.ErrAuthorizationNotFound
+	}
+
+	return &authorization, nil
+}
+
+// List return the authorizations matching the query, oldest first
+func (ar *AuthorizationRepository) List(query ports.AuthorizationQuery) (ports.AuthorizationPage, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	start := 0
+	if !query.From.IsZero() {
+		start = sort.Search(len(ar.authorizations), func(i int) bool {
+			return !ar.authorizations[i].Time.Before(query.From)
+		})
+	}
+
+	end := len(ar.authorizations)
+	if !query.To.IsZero() {
+		end = sort.Search(len(ar.authorizations), func(i int) bool {
+			return !ar.authorizations[i].Time.Before(query.To)
+		})
+	}
+
+	matches := make([]domain.TransactionAuthorization, 0)
+
+	for i := start; i < end; i++ {
+		if query.Merchant != "" && ar.authorizations[i].Merchant != query.Merchant {
+			continue
+		}
+
+		matches = append(matches, ar.authorizations[i])
+	}
+
+	return ports.AuthorizationPage{
+		Authorizations: paginate(matches, query.Offset, query.Limit),
+		Total:          len(matches),
+		Offset:         query.Offset,
+		Limit:          query.Limit,
+	}, nil
+}
+
+// FindNearest return the approved authorization for the merchant and amount closest in time
+// to at, before or after it
+func (ar *AuthorizationRepository) FindNearest(merchant string, amount int64, at time.Time) (*domain.TransactionAuthorization, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	authorizations := ar.index[duplicateKey{merchant: merchant, amount: amount}]
+
+	if len(authorizations) == 0 {
+		return nil, ports.ErrAuthorizationNotFound
+	}
+
+	i := sort.Search(len(authorizations), func(i int) bool {
+		return !authorizations[i].Time.Before(at)
+	})
+
+	if i == len(authorizations) || (i > 0 && at.Sub(authorizations[i-1].Time) <= authorizations[i].Time.Sub(at)) {
+		i--
+	}
+
+	nearest := authorizations[i]
+
+	return &nearest, nil
+}
+
+// LatestApproved return the most recent approved authorization, declines are skipped
+func (ar *AuthorizationRepository) LatestApproved() (*domain.TransactionAuthorization, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	for i := len(ar.authorizations) - 1; i >= 0; i-- {
+		if ar.authorizations[i].Approved() {
+			latest := ar.authorizations[i]
+			return &latest, nil
+		}
+	}
+
+	return nil, ports.ErrAuthorizationNotFound
+}
+
+// Prune drop every authorization older than before and return how many were dropped
+func (ar *AuthorizationRepository) Prune(before time.Time) (int, error) {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	n := sort.Search(len(ar.authorizations), func(i int) bool {
+		return !ar.authorizations[i].Time.Before(before)
+	})
+
+	if n == 0 {
+		return 0, nil
+	}
+
+	for _, authorization := range ar.authorizations[:n] {
+		delete(ar.byID, authorization.ID)
+
+		if !authorization.Approved() {
+			continue
+		}
+
+		key := duplicateKey{merchant: authorization.Merchant, amount: authorization.Amount}
+
+		if remaining := ar.index[key][1:]; len(remaining) > 0 {
+			ar.index[key] = remaining
+		} else {
+			delete(ar.index, key)
+		}
+	}
+
+	ar.authorizations = append([]domain.TransactionAuthorization(nil), ar.authorizations[n:]...)
+
+	return n, nil
+}
+
+func insertOrdered(authorizations []domain.TransactionAuthorization, authorization domain.TransactionAuthorization) []domain.TransactionAuthorization {
+	i := sort.Search(len(authorizations), func(i int) bool {
+		return authorizations[i].Time.After(authorization.Time)
+	})
+
+	authorizations = append(authorizations, domain.TransactionAuthorization{})
+	copy(authorizations[i+1:], authorizations[i:])
+	authorizations[i] = authorization
+
+	return authorizations
+}
+
+func paginate(authorizations []domain.TransactionAuthorization, offset, limit int) []domain.TransactionAuthorization {
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset >= len(authorizations) {
+		return []domain.TransactionAuthorization{}
+	}
+
+	authorizations = authorizations[offset:]
+
+	if limit > 0 && limit < len(authorizations) {
+		authorizations = authorizations[:limit]
+	}
+
+	return authorizations
+}
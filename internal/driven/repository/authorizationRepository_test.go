@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildAuthorizationRepository() *AuthorizationRepository {
+	ar := NewAuthorizationRepository()
+
+	_ = ar.Save(domain.TransactionAuthorization{ID: "1", Merchant: "Burger King", Amount: 20, Time: time.Date(2019, 2, 13, 11, 0, 0, 0, time.UTC)})
+	_ = ar.Save(domain.TransactionAuthorization{ID: "2", Merchant: "Habbib's", Amount: 20, Time: time.Date(2019, 2, 13, 11, 2, 0, 0, time.UTC)})
+	_ = ar.Save(domain.TransactionAuthorization{ID: "3", Merchant: "Burger King", Amount: 20, Time: time.Date(2019, 2, 13, 11, 1, 0, 0, time.UTC)})
+	_ = ar.Save(domain.TransactionAuthorization{ID: "4", Merchant: "Subway", Amount: 15, Time: time.Date(2019, 2, 13, 11, 3, 0, 0, time.UTC)})
+
+	return ar
+}
+
+func merchants(authorizations []domain.TransactionAuthorization) []string {
+	result := make([]string, 0, len(authorizations))
+
+	for _, authorization := range authorizations {
+		result = append(result, authorization.Merchant)
+	}
+
+	return result
+}
+
+func TestAuthorizationRepository_List(t *testing.T) {
+	testCases := []struct {
+		name              string
+		query             ports.AuthorizationQuery
+		expectedMerchants []string
+		expectedTotal     int
+	}{
+		{
+			name:              "listando todo o histórico em ordem de tempo",
+			query:             ports.AuthorizationQuery{},
+			expectedMerchants: []string{"Burger King", "Burger King", "Habbib's", "Subway"},
+			expectedTotal:     4,
+		},
+		{
+			name: "listando uma janela de tempo",
+			query: ports.AuthorizationQuery{
+				From: time.Date(2019, 2, 13, 11, 1, 0, 0, time.UTC),
+				To:   time.Date(2019, 2, 13, 11, 3, 0, 0, time.UTC),
+			},
+			expectedMerchants: []string{"Burger King", "Habbib's"},
+			expectedTotal:     2,
+		},
+		{
+			name:              "filtrando por estabelecimento",
+			query:             ports.AuthorizationQuery{Merchant: "Burger King"},
+			expectedMerchants: []string{"Burger King", "Burger King"},
+			expectedTotal:     2,
+		},
+		{
+			name:              "paginando o histórico",
+			query:             ports.AuthorizationQuery{Offset: 1, Limit: 2},
+			expectedMerchants: []string{"Burger King", "Habbib's"},
+			expectedTotal:     4,
+		},
+		{
+			name:              "paginando além do fim do histórico",
+			query:             ports.AuthorizationQuery{Offset: 10, Limit: 2},
+			expectedMerchants: []string{},
+			expectedTotal:     4,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := buildAuthorizationRepository().List(tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedMerchants, merchants(page.Authorizations))
+			assert.Equal(t, tt.expectedTotal, page.Total)
+		})
+	}
+}
+
+func TestAuthorizationRepository_FindNearest(t *testing.T) {
+	testCases := []struct {
+		name       string
+		at         time.Time
+		expectedID string
+	}{
+		{
+			name:       "buscando depois de todas as autorizações",
+			at:         time.Date(2019, 2, 13, 11, 5, 0, 0, time.UTC),
+			expectedID: "3",
+		},
+		{
+			name:       "buscando antes de todas as autorizações",
+			at:         time.Date(2019, 2, 13, 10, 50, 0, 0, time.UTC),
+			expectedID: "1",
+		},
+		{
+			name:       "buscando mais perto da autorização anterior",
+			at:         time.Date(2019, 2, 13, 11, 0, 20, 0, time.UTC),
+			expectedID: "1",
+		},
+		{
+			name:       "buscando mais perto da autorização posterior",
+			at:         time.Date(2019, 2, 13, 11, 0, 40, 0, time.UTC),
+			expectedID: "3",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			nearest, err := buildAuthorizationRepository().FindNearest("Burger King", 20, tt.at)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedID, nearest.ID)
+		})
+	}
+
+	_, err := buildAuthorizationRepository().FindNearest("Burger King", 25, time.Date(2019, 2, 13, 11, 0, 0, 0, time.UTC))
+
+	assert.ErrorIs(t, err, ports.ErrAuthorizationNotFound)
+}
+
+func TestAuthorizationRepository_FindNearest_Ignores_Declined(t *testing.T) {
+	ar := buildAuthorizationRepository()
+
+	_ = ar.Save(domain.TransactionAuthorization{
+		ID:         "5",
+		Merchant:   "Burger King",
+		Amount:     20,
+		Time:       time.Date(2019, 2, 13, 11, 4, 0, 0, time.UTC),
+		Violations: domain.Violations{domain.InsufficientLimitViolation},
+	})
+
+	nearest, err := ar.FindNearest("Burger King", 20, time.Date(2019, 2, 13, 11, 4, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "3", nearest.ID)
+
+	page, _ := ar.List(ports.AuthorizationQuery{Merchant: "Burger King"})
+	assert.Equal(t, 3, page.Total)
+}
+
+func TestAuthorizationRepository_LatestApproved(t *testing.T) {
+	ar := NewAuthorizationRepository()
+
+	_, err := ar.LatestApproved()
+	assert.ErrorIs(t, err, ports.ErrAuthorizationNotFound)
+
+	ar = buildAuthorizationRepository()
+
+	_ = ar.Save(domain.TransactionAuthorization{
+		ID:         "5",
+		Merchant:   "Burger King",
+		Amount:     20,
+		Time:       time.Date(2019, 2, 13, 11, 4, 0, 0, time.UTC),
+		Violations: domain.Violations{domain.InsufficientLimitViolation},
+	})
+	_ = ar.Save(domain.TransactionAuthorization{ID: "6", Merchant: "Subway", Amount: 10, Time: time.Date(2019, 2, 13, 10, 0, 0, 0, time.UTC)})
+
+	latest, err := ar.LatestApproved()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "4", latest.ID, "declined authorizations and late ones do not move the latest")
+}
+
+func TestAuthorizationRepository_Find(t *testing.T) {
+	ar := buildAuthorizationRepository()
+
+	authorization, err := ar.Find("2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Habbib's", authorization.Merchant)
+
+	_, err = ar.Find("9")
+
+	assert.ErrorIs(t, err, ports.ErrAuthorizationNotFound)
+}
+
+func TestAuthorizationRepository_Prune(t *testing.T) {
+	ar := buildAuthorizationRepository()
+
+	pruned, err := ar.Prune(time.Date(2019, 2, 13, 11, 2, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pruned)
+
+	page, _ := ar.List(ports.AuthorizationQuery{})
+	assert.Equal(t, []string{"Habbib's", "Subway"}, merchants(page.Authorizations))
+
+	_, err = ar.FindNearest("Burger King", 20, time.Date(2019, 2, 13, 11, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ports.ErrAuthorizationNotFound)
+
+	_, err = ar.Find("1")
+	assert.ErrorIs(t, err, ports.ErrAuthorizationNotFound)
+}
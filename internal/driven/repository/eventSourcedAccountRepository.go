@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+// DefaultSnapshotInterval is how many events an EventSourcedAccountRepository folds before
+// taking a new snapshot
+const DefaultSnapshotInterval = 100
+
+// errNoEvents is returned when a change carries no event, the stream is the only state kept
+var errNoEvents = errors.New("account change without events")
+
+// accountSnapshot is the account folded from the first version events of the stream
+type accountSnapshot struct {
+	account domain.Account
+	version int
+}
+
+// EventSourcedAccountRepository keeps the account as an append-only stream of domain events
+// and rebuilds it by folding them with domain.Account.Apply, starting from the latest snapshot.
+// The account handed to Create and Update is not stored, its events are. Declines have no
+// account change and reach the stream through Append, so the stream holds every decision and
+// the authorization history served by History is projected from it. Every event is also stored
+// in the outbox, in the same write
+type EventSourcedAccountRepository struct {
+	mu               sync.RWMutex
+	stream           []domain.Event
+	created          bool
+	snapshot         *accountSnapshot
+	snapshotInterval int
+	outbox           *InMemoryOutbox
+	history          *AuthorizationRepository
+}
+
+// NewEventSourcedAccountRepository create a new EventSourcedAccountRepository instance taking a
+// snapshot every snapshotInterval events, DefaultSnapshotInterval when it is not positive, and
+// stamping outbox entries on the clock
+func NewEventSourcedAccountRepository(c ports.Clock, snapshotInterval int) *EventSourcedAccountRepository {
+	if snapshotInterval <= 0 {
+		snapshotInterval = DefaultSnapshotInterval
+	}
+
+	return &EventSourcedAccountRepository{
+		snapshotInterval: snapshotInterval,
+		outbox:           NewInMemoryOutbox(c),
+		history:          NewAuthorizationRepository(),
+	}
+}
+
+// Create append the events of a new account, one of them must be AccountCreated
+func (ar *EventSourcedAccountRepository) Create(_ domain.Account, events ...domain.Event) error {
+	if !containsAccountCreated(events) {
+		return &ports.StorageError{Op: "create", Err: errNoEvents}
+	}
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if ar.created {
+		return ports.ErrAccountAlreadyExists
+	}
+
+	ar.append(events)
+
+	return nil
+}
+
+// Update append the events of the account change
+func (ar *EventSourcedAccountRepository) Update(_ domain.Account, events ...domain.Event) error {
+	if len(events) == 0 {
+		return &ports.StorageError{Op: "update", Err: errNoEvents}
+	}
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if !ar.created {
+		return ports.ErrAccountNotFound
+	}
+
+	ar.append(events)
+
+	return nil
+}
+
+// Retrieve fold the stream from the latest snapshot and return the account with the
+// accumulators rebuilt for currentTime
+func (ar *EventSourcedAccountRepository) Retrieve(currentTime time.Time) (*domain.Account, error) {
+	ar.mu.RLock()
+	defer ar.mu.RUnlock()
+
+	if !ar.created {
+		return nil, ports.ErrAccountNotFound
+	}
+
+	account := ar.fold()
+	rebuildAccumulators(&account, currentTime)
+
+	return &account, nil
+}
+
+// History return the authorization history projected from the approvals and declines of the
+// stream, the transaction service records its decisions in it
+func (ar *EventSourcedAccountRepository) History() ports.AuthorizationRepository {
+	return projectedHistory{AuthorizationRepository: ar.history}
+}
+
+// Append store events that do not change the account, such as declines
+func (ar *EventSourcedAccountRepository) Append(events ...domain.Event) error {
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	ar.append(events)
+
+	return nil
+}
+
+// Pending return up to limit entries of the outbox not delivered yet, oldest first
+func (ar *EventSourcedAccountRepository) Pending(limit int) ([]domain.OutboxEntry, error) {
+	return ar.outbox.Pending(limit)
+}
+
+// MarkDelivered record a successful delivery attempt of an outbox entry
+func (ar *EventSourcedAccountRepository) MarkDelivered(id int64, at time.Time) error {
+	return ar.outbox.MarkDelivered(id, at)
+}
+
+// MarkFailed record a failed delivery attempt of an outbox entry
+func (ar *EventSourcedAccountRepository) MarkFailed(id int64, at time.Time, cause error) error {
+	return ar.outbox.MarkFailed(id, at, cause)
+}
+
+// Prune remove the outbox entries delivered before the given time, the stream is kept whole
+func (ar *EventSourcedAccountRepository) Prune(before time.Time) (int, error) {
+	return ar.outbox.Prune(before)
+}
+
+// append add the events to the stream and the outbox and take a snapshot once enough events
+// were appended since the last one, the caller holds the lock
+func (ar *EventSourcedAccountRepository) append(events []domain.Event) {
+	ar.outbox.mu.Lock()
+	ar.stream = append(ar.stream, events...)
+	ar.outbox.append(events)
+	ar.outbox.mu.Unlock()
+
+	for _, event := range events {
+		if authorization, ok := authorizationOf(event); ok {
+			_ = ar.history.Save(authorization)
+		}
+	}
+
+	ar.created = ar.created || containsAccountCreated(events)
+
+	version := 0
+	if ar.snapshot != nil {
+		version = ar.snapshot.version
+	}
+
+	if len(ar.stream)-version >= ar.snapshotInterval {
+		ar.snapshot = &accountSnapshot{account: ar.fold(), version: len(ar.stream)}
+	}
+}
+
+// fold apply the events after the latest snapshot to a copy of it, the caller holds the lock
+func (ar *EventSourcedAccountRepository) fold() domain.Account {
+	var account domain.Account
+	version := 0
+
+	if ar.snapshot != nil {
+		account = cloneAccount(ar.snapshot.account)
+		version = ar.snapshot.version
+	}
+
+	for _, event := range ar.stream[version:] {
+		account.Apply(event)
+	}
+
+	return account
+}
+
+// authorizationOf return the authorization an approval or a decline records. An approval carries
+// the available limit after it, the authorization keeps the one it was decided on
+func authorizationOf(event domain.Event) (domain.TransactionAuthorization, bool) {
+	switch e := event.(type) {
+	case domain.TransactionApproved:
+		return domain.TransactionAuthorization{
+			ID:             e.AuthorizationID,
+			Merchant:       e.Merchant,
+			Amount:         e.Amount,
+			AvailableLimit: e.AvailableLimit + e.Amount,
+			Time:           e.Time,
+			Violations:     domain.Violations{},
+		}, true
+	case domain.TransactionDeclined:
+		return domain.TransactionAuthorization{
+			ID:             e.AuthorizationID,
+			Merchant:       e.Merchant,
+			Amount:         e.Amount,
+			AvailableLimit: e.AvailableLimit,
+			Time:           e.Time,
+			Violations:     e.Violations,
+		}, true
+	default:
+		return domain.TransactionAuthorization{}, false
+	}
+}
+
+// projectedHistory is the authorization history of an EventSourcedAccountRepository, the
+// authorizations are projected when their events are appended so Save has nothing left to do
+type projectedHistory struct {
+	*AuthorizationRepository
+}
+
+// Save do nothing, the authorization was projected from its event
+func (projectedHistory) Save(domain.TransactionAuthorization) error {
+	return nil
+}
+
+func containsAccountCreated(events []domain.Event) bool {
+	for _, event := range events {
+		if _, ok := event.(domain.AccountCreated); ok {
+			return true
+		}
+	}
+
+	return false
+}
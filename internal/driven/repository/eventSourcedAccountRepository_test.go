@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventSourcedAccountRepository(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	created := domain.AccountCreated{
+		AccountID: domain.DefaultAccountID,
+		Ledger:    domain.Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 100},
+		Rules: []domain.Rule{
+			{Name: "max transactions in 2 minutes", Type: "usage-limit", UsageLimit: 3, Accumulator: &domain.Accumulator{Duration: 2 * time.Minute}},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		snapshotInterval int
+	}{
+		{name: "snapshot a cada evento", snapshotInterval: 1},
+		{name: "snapshot a cada dois eventos", snapshotInterval: 2},
+		{name: "sem snapshot", snapshotInterval: 0},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ar := NewEventSourcedAccountRepository(clock.NewSystemClock(), tt.snapshotInterval)
+
+			_, err := ar.Retrieve(baseTime)
+			assert.ErrorIs(t, err, ports.ErrAccountNotFound)
+			assert.ErrorIs(t, ar.Update(domain.Account{}, domain.TransactionApproved{Amount: 10, Time: baseTime}), ports.ErrAccountNotFound)
+
+			assert.NoError(t, ar.Append(domain.TransactionDeclined{Violations: domain.Violations{domain.AccountNotInitializedViolation}, Time: baseTime}))
+			assert.NoError(t, ar.Create(domain.Account{}, created))
+			assert.ErrorIs(t, ar.Create(domain.Account{}, created), ports.ErrAccountAlreadyExists)
+
+			assert.NoError(t, ar.Update(domain.Account{}, domain.TransactionApproved{Amount: 10, Time: baseTime}))
+			assert.NoError(t, ar.Append(domain.TransactionDeclined{Amount: 500, Time: baseTime.Add(time.Second)}))
+			assert.NoError(t, ar.Update(domain.Account{}, domain.TransactionApproved{Amount: 20, Time: baseTime.Add(time.Minute)}))
+
+			account, err := ar.Retrieve(baseTime.Add(time.Minute))
+
+			assert.NoError(t, err)
+			assert.Equal(t, int64(70), account.Ledger.AvailableLimit)
+			assert.Equal(t, domain.Accumulator{
+				Duration:           2 * time.Minute,
+				CurrentPeriodUsed:  2,
+				CurrentPeriodSpend: 30,
+				PeriodEndsDate:     baseTime.Add(2 * time.Minute),
+			}, *account.SpendingControl.Rules[0].Accumulator)
+
+			account.SpendingControl.Rules[0].Accumulator.AddSpend(1000)
+
+			later, _ := ar.Retrieve(baseTime.Add(5 * time.Minute))
+			assert.Equal(t, int64(0), later.SpendingControl.Rules[0].Accumulator.CurrentPeriodUsed, "the period is rebuilt for the time asked")
+
+			assert.Len(t, ar.stream, 5, "declines are part of the stream")
+
+			page, _ := ar.History().List(ports.AuthorizationQuery{})
+			assert.Len(t, page.Authorizations, 4, "every approval and decline is projected to the history")
+
+			pending, _ := ar.Pending(0)
+			assert.Len(t, pending, 5, "every event reaches the outbox")
+		})
+	}
+}
+
+func TestEventSourcedAccountRepository_Without_Events(t *testing.T) {
+	ar := NewEventSourcedAccountRepository(clock.NewSystemClock(), 0)
+
+	var storageErr *ports.StorageError
+
+	assert.ErrorAs(t, ar.Create(domain.Account{}), &storageErr)
+
+	_ = ar.Create(domain.Account{}, domain.AccountCreated{AccountID: domain.DefaultAccountID})
+
+	assert.ErrorAs(t, ar.Update(domain.Account{}), &storageErr)
+}
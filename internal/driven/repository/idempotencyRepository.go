@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+// IdempotencyRepository keeps the recorded responses in memory by key
+type IdempotencyRepository struct {
+	mu      sync.RWMutex
+	records map[string]domain.IdempotencyRecord
+}
+
+// NewIdempotencyRepository create a new IdempotencyRepository instance
+func NewIdempotencyRepository() *IdempotencyRepository {
+	return &IdempotencyRepository{records: make(map[string]domain.IdempotencyRecord)}
+}
+
+// Save insert the record, replacing any other stored under the same key
+func (ir *IdempotencyRepository) Save(record domain.IdempotencyRecord) error {
+	record.Response = append([]byte(nil), record.Response...)
+
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	ir.records[record.Key] = record
+
+	return nil
+}
+
+// Find return the record stored under the key
+func (ir *IdempotencyRepository) Find(key string) (*domain.IdempotencyRecord, error) {
+	ir.mu.RLock()
+	defer ir.mu.RUnlock()
+
+	record, exists := ir.records[key]
+
+	if !exists {
+		return nil, ports.ErrIdempotencyKeyNotFound
+	}
+
+	record.Response = append([]byte(nil), record.Response...)
+
+	return &record, nil
+}
+
+// Prune drop every record created before the given instant and return how many were dropped
+func (ir *IdempotencyRepository) Prune(before time.Time) (int, error) {
+	ir.mu.Lock()
+	defer ir.mu.Unlock()
+
+	pruned := 0
+
+	for key, record := range ir.records {
+		if record.CreatedAt.Before(before) {
+			delete(ir.records, key)
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	ir := NewIdempotencyRepository()
+
+	response := []byte(`{"violations":[]}`)
+
+	_ = ir.Save(domain.IdempotencyRecord{Key: "a", Fingerprint: "x", Response: response, CreatedAt: time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)})
+	_ = ir.Save(domain.IdempotencyRecord{Key: "b", Fingerprint: "y", Response: response, CreatedAt: time.Date(2021, 10, 10, 11, 0, 0, 0, time.UTC)})
+
+	response[0] = '['
+
+	record, err := ir.Find("a")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "x", record.Fingerprint)
+	assert.Equal(t, `{"violations":[]}`, string(record.Response), "the stored response is a private copy")
+
+	pruned, err := ir.Prune(time.Date(2021, 10, 10, 10, 30, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+
+	_, err = ir.Find("a")
+	assert.ErrorIs(t, err, ports.ErrIdempotencyKeyNotFound)
+
+	_, err = ir.Find("b")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+// InMemoryAccountRepository keeps domain.Account snapshots in memory without encoding them,
+// every write stores a private copy and every read hands out a new one
+type InMemoryAccountRepository struct {
+	mu       sync.RWMutex
+	accounts map[int64]*domain.Account
+	outbox   *InMemoryOutbox
+}
+
+// NewInMemoryAccountRepository create a new InMemoryAccountRepository instance with its own outbox,
+// stamping entries on the clock
+func NewInMemoryAccountRepository(c ports.Clock) *InMemoryAccountRepository {
+	return &InMemoryAccountRepository{accounts: make(map[int64]*domain.Account), outbox: NewInMemoryOutbox(c)}
+}
+
+// Outbox return the outbox the events of every change are stored in
+func (ar *InMemoryAccountRepository) Outbox() *InMemoryOutbox {
+	return ar.outbox
+}
+
+// Create insert new account and its events
+func (ar *InMemoryAccountRepository) Create(account domain.Account, events ...domain.Event) error {
+	snapshot := cloneAccount(account)
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if _, exists := ar.accounts[domain.DefaultAccountID]; exists {
+		return ports.ErrAccountAlreadyExists
+	}
+
+	ar.store(&snapshot, events)
+
+	return nil
+}
+
+// Update replace the stored account and append its events
+func (ar *InMemoryAccountRepository) Update(account domain.Account, events ...domain.Event) error {
+	snapshot := cloneAccount(account)
+
+	ar.mu.Lock()
+	defer ar.mu.Unlock()
+
+	if _, exists := ar.accounts[domain.DefaultAccountID]; !exists {
+		return ports.ErrAccountNotFound
+	}
+
+	ar.store(&snapshot, events)
+
+	return nil
+}
+
+// store write the account and its events while holding both locks, so no reader sees one
+// without the other, the caller holds the account lock
+func (ar *InMemoryAccountRepository) store(snapshot *domain.Account, events []domain.Event) {
+	ar.outbox.mu.Lock()
+	defer ar.outbox.mu.Unlock()
+
+	ar.accounts[domain.DefaultAccountID] = snapshot
+	ar.outbox.append(events)
+}
+
+// Retrieve find account and return a copy with the accumulators rebuilt for currentTime
+func (ar *InMemoryAccountRepository) Retrieve(currentTime time.Time) (*domain.Account, error) {
+	ar.mu.RLock()
+	snapshot, exists := ar.accounts[domain.DefaultAccountID]
+	ar.mu.RUnlock()
+
+	if !exists {
+		return nil, ports.ErrAccountNotFound
+	}
+
+	account := cloneAccount(*snapshot)
+	rebuildAccumulators(&account, currentTime)
+
+	return &account, nil
+}
+
+// rebuildAccumulators move every rule accumulator to its period at currentTime
+func rebuildAccumulators(account *domain.Account, currentTime time.Time) {
+	for i, rule := range account.SpendingControl.Rules {
+		if rule.Accumulator == nil {
+			continue
+		}
+
+		accumulator := domain.BuildAccumulator(
+			currentTime,
+			rule.Accumulator.Duration,
+			rule.Accumulator.CurrentPeriodUsed,
+			rule.Accumulator.CurrentPeriodSpend,
+			rule.Accumulator.PeriodEndsDate,
+		)
+
+		account.SpendingControl.Rules[i].Accumulator = &accumulator
+	}
+}
+
+func cloneAccount(account domain.Account) domain.Account {
+	rules := make([]domain.Rule, 0, len(account.SpendingControl.Rules))
+
+	for _, rule := range account.SpendingControl.Rules {
+		if rule.Accumulator != nil {
+			accumulator := *rule.Accumulator
+			rule.Accumulator = &accumulator
+		}
+
+		rules = append(rules, rule)
+	}
+
+	account.SpendingControl.Rules = rules
+
+	return account
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildAccount() domain.Account {
+	return domain.Account{
+		Ledger: domain.Ledger{
+			ActiveCard:     true,
+			MaxLimit:       500,
+			AvailableLimit: 450,
+		},
+		SpendingControl: domain.SpendingControl{
+			Rules: []domain.Rule{
+				{
+					Name:       "max transactions in 2 minutes",
+					Type:       "usage-limit",
+					UsageLimit: 3,
+					Accumulator: &domain.Accumulator{
+						Duration:           2 * time.Minute,
+						CurrentPeriodUsed:  2,
+						CurrentPeriodSpend: 50,
+						PeriodEndsDate:     time.Date(2021, 10, 10, 10, 2, 0, 0, time.UTC),
+					},
+					RuleViolation: "high-frequency-small-interval",
+				},
+			},
+		},
+	}
+}
+
+func TestInMemoryAccountRepository_Isolation(t *testing.T) {
+	ar := NewInMemoryAccountRepository(clock.NewSystemClock())
+	account := buildAccount()
+
+	assert.NoError(t, ar.Create(account))
+
+	account.Ledger.AvailableLimit = 0
+	account.SpendingControl.Rules[0].Accumulator.CurrentPeriodUsed = 10
+
+	retrieved, err := ar.Retrieve(time.Date(2021, 10, 10, 10, 1, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(450), retrieved.Ledger.AvailableLimit)
+	assert.Equal(t, int64(2), retrieved.SpendingControl.Rules[0].Accumulator.CurrentPeriodUsed)
+
+	retrieved.SpendingControl.Rules[0].Accumulator.AddSpend(25)
+
+	again, _ := ar.Retrieve(time.Date(2021, 10, 10, 10, 1, 0, 0, time.UTC))
+
+	assert.Equal(t, int64(2), again.SpendingControl.Rules[0].Accumulator.CurrentPeriodUsed)
+}
+
+func TestInMemoryAccountRepository_MatchesAccountRepository(t *testing.T) {
+	typed := NewInMemoryAccountRepository(clock.NewSystemClock())
+	encoded := NewAccountRepository(database.NewInMemoryDB(), NewAuthorizationRepository(), clock.NewSystemClock())
+
+	for _, ar := range []ports.AccountRepository{typed, encoded} {
+		assert.NoError(t, ar.Create(buildAccount()))
+		assert.ErrorIs(t, ar.Create(buildAccount()), ports.ErrAccountAlreadyExists)
+	}
+
+	for _, currentTime := range []time.Time{
+		time.Date(2021, 10, 10, 10, 1, 0, 0, time.UTC),
+		time.Date(2021, 10, 10, 10, 5, 0, 0, time.UTC),
+	} {
+		expected, _ := encoded.Retrieve(currentTime)
+		result, _ := typed.Retrieve(currentTime)
+
+		assert.Equal(t, expected, result)
+	}
+}
+
+func TestInMemoryAccountRepository_Not_Found(t *testing.T) {
+	ar := NewInMemoryAccountRepository(clock.NewSystemClock())
+
+	_, err := ar.Retrieve(time.Now())
+	assert.ErrorIs(t, err, ports.ErrAccountNotFound)
+
+	err = ar.Update(buildAccount())
+	assert.ErrorIs(t, err, ports.ErrAccountNotFound)
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
+// InMemoryOutbox keeps the outbox entries in memory, in ID order
+type InMemoryOutbox struct {
+	mu      sync.Mutex
+	entries []domain.OutboxEntry
+	lastID  int64
+	clock   ports.Clock
+}
+
+// NewInMemoryOutbox create a new InMemoryOutbox instance stamping entries on the clock
+func NewInMemoryOutbox(c ports.Clock) *InMemoryOutbox {
+	return &InMemoryOutbox{clock: c}
+}
+
+// Append store the events under new IDs
+func (o *InMemoryOutbox) Append(events ...domain.Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.append(events)
+
+	return nil
+}
+
+// append store the events, the caller holds the lock
+func (o *InMemoryOutbox) append(events []domain.Event) {
+	createdAt := o.clock.Now()
+
+	for _, event := range events {
+		o.lastID++
+		o.entries = append(o.entries, domain.OutboxEntry{ID: o.lastID, Event: event, CreatedAt: createdAt})
+	}
+}
+
+// Pending return up to limit entries not delivered yet, oldest first, every one of them
+// when limit is not positive
+func (o *InMemoryOutbox) Pending(limit int) ([]domain.OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	pending := make([]domain.OutboxEntry, 0)
+
+	for _, entry := range o.entries {
+		if limit > 0 && len(pending) == limit {
+			break
+		}
+
+		if !entry.Delivered() {
+			pending = append(pending, entry)
+		}
+	}
+
+	return pending, nil
+}
+
+// MarkDelivered record a successful delivery attempt
+func (o *InMemoryOutbox) MarkDelivered(id int64, at time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry, err := o.find(id)
+	if err != nil {
+		return err
+	}
+
+	entry.Attempts++
+	entry.LastAttemptAt = at
+	entry.LastError = ""
+	entry.DeliveredAt = at
+
+	return nil
+}
+
+// MarkFailed record a failed delivery attempt, the entry stays pending
+func (o *InMemoryOutbox) MarkFailed(id int64, at time.Time, cause error) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entry, err := o.find(id)
+	if err != nil {
+		return err
+	}
+
+	entry.Attempts++
+	entry.LastAttemptAt = at
+	entry.LastError = cause.Error()
+
+	return nil
+}
+
+// Prune remove the entries delivered before the given time and return how many were removed
+func (o *InMemoryOutbox) Prune(before time.Time) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	kept := o.entries[:0]
+
+	for _, entry := range o.entries {
+		if entry.Delivered() && entry.DeliveredAt.Before(before) {
+			continue
+		}
+
+		kept = append(kept, entry)
+	}
+
+	pruned := len(o.entries) - len(kept)
+	o.entries = kept
+
+	return pruned, nil
+}
+
+// find return the entry stored under the ID, the caller holds the lock
+func (o *InMemoryOutbox) find(id int64) (*domain.OutboxEntry, error) {
+	i := sort.Search(len(o.entries), func(i int) bool { return o.entries[i].ID >= id })
+
+	if i == len(o.entries) || o.entries[i].ID != id {
+		return nil, ports.ErrOutboxEntryNotFound
+	}
+
+	return &o.entries[i], nil
+}
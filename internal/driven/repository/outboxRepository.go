@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/database"
+	"github.com/authorizer/internal/dto"
+)
+
+// outboxTable holds the outbox entries written by OutboxRepository and AccountRepository
+const outboxTable = "outbox"
+
+// OutboxRepository represents the outbox kept on DB next to the accounts
+type OutboxRepository struct {
+	db    database.DB
+	clock ports.Clock
+}
+
+// NewOutboxRepository create a new OutboxRepository instance stamping entries on the clock
+func NewOutboxRepository(db database.DB, c ports.Clock) OutboxRepository {
+	return OutboxRepository{db: db, clock: c}
+}
+
+// Append insert the events under new IDs
+func (or OutboxRepository) Append(events ...domain.Event) error {
+	writes, err := outboxWrites(or.db, or.clock.Now(), events)
+	if err != nil {
+		return storageError("append events", err)
+	}
+
+	return storageError("append events", or.db.Batch(writes...))
+}
+
+// Pending return up to limit entries not delivered yet, oldest first, every one of them
+// when limit is not positive
+func (or OutboxRepository) Pending(limit int) ([]domain.OutboxEntry, error) {
+	pending := make([]domain.OutboxEntry, 0)
+
+	errLimitReached := errors.New("limit reached")
+
+	err := or.db.Scan(outboxTable, func(id int64, decode func(target interface{}) error) error {
+		if limit > 0 && len(pending) == limit {
+			return errLimitReached
+		}
+
+		var entryDTO dto.OutboxEntry
+		if err := decode(&entryDTO); err != nil {
+			return err
+		}
+
+		if !entryDTO.DeliveredAt.IsZero() {
+			return nil
+		}
+
+		entry, err := buildDomainOutboxEntry(id, entryDTO)
+		if err != nil {
+			return err
+		}
+
+		pending = append(pending, entry)
+
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, storageError("pending events", err)
+	}
+
+	return pending, nil
+}
+
+// MarkDelivered record a successful delivery attempt
+func (or OutboxRepository) MarkDelivered(id int64, at time.Time) error {
+	return or.mark(id, func(entry *dto.OutboxEntry) {
+		entry.Attempts++
+		entry.LastAttemptAt = at
+		entry.LastError = ""
+		entry.DeliveredAt = at
+	})
+}
+
+// MarkFailed record a failed delivery attempt, the entry stays pending
+func (or OutboxRepository) MarkFailed(id int64, at time.Time, cause error) error {
+	return or.mark(id, func(entry *dto.OutboxEntry) {
+		entry.Attempts++
+		entry.LastAttemptAt = at
+		entry.LastError = cause.Error()
+	})
+}
+
+// Prune delete the entries delivered before the given time and return how many were deleted
+func (or OutboxRepository) Prune(before time.Time) (int, error) {
+	var delivered []int64
+
+	err := or.db.Scan(outboxTable, func(id int64, decode func(target interface{}) error) error {
+		var entryDTO dto.OutboxEntry
+		if err := decode(&entryDTO); err != nil {
+			return err
+		}
+
+		if !entryDTO.DeliveredAt.IsZero() && entryDTO.DeliveredAt.Before(before) {
+			delivered = append(delivered, id)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return 0, storageError("prune events", err)
+	}
+
+	for i, id := range delivered {
+		if err := or.db.Delete(outboxTable, id); err != nil {
+			return i, storageError("prune events", err)
+		}
+	}
+
+	return len(delivered), nil
+}
+
+func (or OutboxRepository) mark(id int64, change func(entry *dto.OutboxEntry)) error {
+	var entryDTO dto.OutboxEntry
+	err := or.db.Find(outboxTable, id, &entryDTO)
+
+	if errors.Is(err, database.ErrNoRecords) {
+		return ports.ErrOutboxEntryNotFound
+	}
+
+	if err != nil {
+		return storageError("mark event", err)
+	}
+
+	change(&entryDTO)
+
+	return storageError("mark event", or.db.Update(outboxTable, id, entryDTO))
+}
+
+// outboxWrites build the inserts of the events into the outbox, to be applied in the
+// same batch as the change they came from
+func outboxWrites(db database.DB, createdAt time.Time, events []domain.Event) ([]database.Write, error) {
+	writes := make([]database.Write, 0, len(events))
+
+	for _, event := range events {
+		id, err := db.NextID(outboxTable)
+		if err != nil {
+			return nil, err
+		}
+
+		writes = append(writes, database.Write{
+			Table:  outboxTable,
+			ID:     id,
+			Data:   dto.OutboxEntry{Event: dto.NewEvent(event), CreatedAt: createdAt},
+			Insert: true,
+		})
+	}
+
+	return writes, nil
+}
+
+func buildDomainOutboxEntry(id int64, entryDTO dto.OutboxEntry) (domain.OutboxEntry, error) {
+	event, err := dto.BuildDomainEvent(entryDTO.Event)
+	if err != nil {
+		return domain.OutboxEntry{}, err
+	}
+
+	return domain.OutboxEntry{
+		ID:            id,
+		Event:         event,
+		CreatedAt:     entryDTO.CreatedAt,
+		Attempts:      entryDTO.Attempts,
+		LastAttemptAt: entryDTO.LastAttemptAt,
+		LastError:     entryDTO.LastError,
+		DeliveredAt:   entryDTO.DeliveredAt,
+	}, nil
+}
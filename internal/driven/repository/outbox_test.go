@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbox(t *testing.T) {
+	eventTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name   string
+		outbox func() ports.Outbox
+	}{
+		{
+			name:   "outbox em memória",
+			outbox: func() ports.Outbox { return NewInMemoryOutbox(clock.NewSystemClock()) },
+		},
+		{
+			name:   "outbox no banco de dados",
+			outbox: func() ports.Outbox { return NewOutboxRepository(database.NewInMemoryDB(), clock.NewSystemClock()) },
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			o := tt.outbox()
+
+			_ = o.Append(
+				domain.TransactionApproved{AccountID: 1, AuthorizationID: "1", Merchant: "Vivara", Amount: 10, AvailableLimit: 90, Time: eventTime},
+				domain.TransactionApproved{AccountID: 1, AuthorizationID: "2", Merchant: "Vivara", Amount: 10, AvailableLimit: 80, Time: eventTime},
+			)
+			_ = o.Append(domain.TransactionDeclined{AccountID: 1, AuthorizationID: "3", Violations: domain.Violations{"insufficient-limit"}, Time: eventTime})
+
+			pending, err := o.Pending(2)
+
+			assert.NoError(t, err)
+			assert.Len(t, pending, 2)
+			assert.Equal(t, "1", pending[0].Event.(domain.TransactionApproved).AuthorizationID)
+			assert.Equal(t, "2", pending[1].Event.(domain.TransactionApproved).AuthorizationID)
+
+			first, second := pending[0].ID, pending[1].ID
+
+			assert.NoError(t, o.MarkFailed(first, eventTime, errors.New("broker down")))
+			assert.NoError(t, o.MarkDelivered(first, eventTime.Add(time.Minute)))
+			assert.NoError(t, o.MarkFailed(second, eventTime, errors.New("broker down")))
+			assert.ErrorIs(t, o.MarkDelivered(999, eventTime), ports.ErrOutboxEntryNotFound)
+
+			pending, _ = o.Pending(0)
+
+			assert.Len(t, pending, 2, "delivered entries are no longer pending")
+			assert.Equal(t, second, pending[0].ID)
+			assert.Equal(t, 1, pending[0].Attempts)
+			assert.Equal(t, "broker down", pending[0].LastError)
+			assert.Equal(t, domain.TransactionDeclined{AccountID: 1, AuthorizationID: "3", Violations: domain.Violations{"insufficient-limit"}, Time: eventTime}, pending[1].Event)
+
+			pruned, err := o.Prune(eventTime.Add(time.Minute))
+			assert.NoError(t, err)
+			assert.Equal(t, 0, pruned, "entries delivered at the limit are kept")
+
+			pruned, err = o.Prune(eventTime.Add(2 * time.Minute))
+			assert.NoError(t, err)
+			assert.Equal(t, 1, pruned)
+
+			assert.ErrorIs(t, o.MarkDelivered(first, eventTime), ports.ErrOutboxEntryNotFound)
+		})
+	}
+}
+
+func TestAccountRepository_Stores_Events_With_Account(t *testing.T) {
+	created := domain.AccountCreated{AccountID: 1, Ledger: domain.Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 100}, Rules: []domain.Rule{}}
+	approved := domain.TransactionApproved{AccountID: 1, AuthorizationID: "1", Amount: 10, AvailableLimit: 90}
+
+	t.Run("repositório em memória", func(t *testing.T) {
+		ar := NewInMemoryAccountRepository(clock.NewSystemClock())
+
+		assert.ErrorIs(t, ar.Update(domain.Account{}, approved), ports.ErrAccountNotFound)
+		assert.NoError(t, ar.Create(domain.Account{Ledger: created.Ledger}, created))
+		assert.ErrorIs(t, ar.Create(domain.Account{}, created), ports.ErrAccountAlreadyExists)
+		assert.NoError(t, ar.Update(domain.Account{Ledger: domain.Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 90}}, approved))
+
+		pending, _ := ar.Outbox().Pending(0)
+
+		assert.Len(t, pending, 2, "events of failed writes are not stored")
+		assert.Equal(t, created, pending[0].Event)
+		assert.Equal(t, approved, pending[1].Event)
+	})
+
+	t.Run("repositório no banco de dados", func(t *testing.T) {
+		db := database.NewInMemoryDB()
+		ar := NewAccountRepository(db, NewAuthorizationRepository(), clock.NewSystemClock())
+		o := NewOutboxRepository(db, clock.NewSystemClock())
+
+		assert.ErrorIs(t, ar.Update(domain.Account{}, approved), ports.ErrAccountNotFound)
+		assert.NoError(t, ar.Create(domain.Account{Ledger: created.Ledger}, created))
+		assert.ErrorIs(t, ar.Create(domain.Account{}, created), ports.ErrAccountAlreadyExists)
+		assert.NoError(t, ar.Update(domain.Account{Ledger: domain.Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 90}}, approved))
+
+		pending, _ := o.Pending(0)
+
+		assert.Len(t, pending, 2, "events of failed writes are not stored")
+		assert.Equal(t, created, pending[0].Event)
+		assert.Equal(t, approved, pending[1].Event)
+	})
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/dto"
+)
+
+// currentSchemaVersion is the version written on every stored dto.Account
+const currentSchemaVersion = 2
+
+// storedAccount is a stored account read at any schema version, it holds the fields every
+// version wrote so upgrades work on typed values and numbers keep their int64 precision
+type storedAccount struct {
+	SchemaVersion   *int                `json:"schema_version"`
+	Ledger          dto.Ledger          `json:"ledger"`
+	SpendingControl dto.SpendingControl `json:"spending_control"`
+	Transactions    []transactionV1     `json:"transactions"`
+
+	// migrated are the authorizations upgrades moved out of the record into the history
+	migrated []domain.TransactionAuthorization
+}
+
+// transactionV1 is an approved transaction as kept inside the account up to version 1
+type transactionV1 struct {
+	Merchant       string    `json:"merchant"`
+	Amount         int64     `json:"amount"`
+	AvailableLimit int64     `json:"available_limit"`
+	Time           time.Time `json:"time"`
+}
+
+// upgradeFunc moves a stored record from its version to the next one
+type upgradeFunc func(record *storedAccount) error
+
+// upgrades holds one upgradeFunc per historical schema version, keyed by the version it upgrades from
+var upgrades = map[int]upgradeFunc{
+	1: upgradeV1ToV2,
+}
+
+// upgradeV1ToV2 moves the authorization history kept inside the account to the authorization
+// repository, where it now lives. Version 1 kept no IDs, each authorization is given one from
+// its position in the record so an upgrade that runs again finds the ones already moved
+func upgradeV1ToV2(record *storedAccount) error {
+	for i, transaction := range record.Transactions {
+		record.migrated = append(record.migrated, domain.TransactionAuthorization{
+			ID:             fmt.Sprintf("v1-%d", i+1),
+			Merchant:       transaction.Merchant,
+			Amount:         transaction.Amount,
+			AvailableLimit: transaction.AvailableLimit,
+			Time:           transaction.Time,
+			Violations:     domain.Violations{},
+		})
+	}
+
+	record.Transactions = nil
+
+	return nil
+}
+
+// decodeAccount upgrade the stored record to the current schema. It returns the version the
+// record was stored at and the authorizations the upgrades moved out of it
+func decodeAccount(raw json.RawMessage) (dto.Account, int, []domain.TransactionAuthorization, error) {
+	var record storedAccount
+
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return dto.Account{}, 0, nil, err
+	}
+
+	stored := 1
+	if record.SchemaVersion != nil {
+		stored = *record.SchemaVersion
+	}
+
+	if stored < 1 || stored > currentSchemaVersion {
+		return dto.Account{}, 0, nil, fmt.Errorf("unsupported schema version %d", stored)
+	}
+
+	for version := stored; version < currentSchemaVersion; version++ {
+		upgrade, exists := upgrades[version]
+		if !exists {
+			return dto.Account{}, 0, nil, fmt.Errorf("no upgrade registered from schema version %d", version)
+		}
+
+		if err := upgrade(&record); err != nil {
+			return dto.Account{}, 0, nil, fmt.Errorf("upgrading from schema version %d: %w", version, err)
+		}
+	}
+
+	return dto.Account{
+		SchemaVersion:   currentSchemaVersion,
+		Ledger:          record.Ledger,
+		SpendingControl: record.SpendingControl,
+	}, stored, record.migrated, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/database"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	accountV1 = `{
+		"ledger":{"active":true,"max_limit":500,"available_limit":450},
+		"spending_control":{"rules":[{"name":"max transactions in 2 minutes","type":"usage-limit","usage_limit":3,
+			"accumulator":{"duration":120000000000,"current_period_used":2,"current_period_spend":50,"period_ends_date":"2021-10-10T10:02:00Z"},
+			"rule_violation":"high-frequency-small-interval"}]},
+		"transactions":[{"merchant":"Merchant1","amount":50,"available_limit":500,"time":"2021-10-10T10:00:00Z"}]
+	}`
+	accountV1WithoutTransactions = `{
+		"ledger":{"active":true,"max_limit":500,"available_limit":450},
+		"spending_control":{"rules":[{"name":"max transactions in 2 minutes","type":"usage-limit","usage_limit":3,
+			"accumulator":{"duration":120000000000,"current_period_used":2,"current_period_spend":50,"period_ends_date":"2021-10-10T10:02:00Z"},
+			"rule_violation":"high-frequency-small-interval"}]}
+	}`
+	accountV2 = `{
+		"schema_version":2,
+		"ledger":{"active":true,"max_limit":500,"available_limit":450},
+		"spending_control":{"rules":[{"name":"max transactions in 2 minutes","type":"usage-limit","usage_limit":3,
+			"accumulator":{"duration":120000000000,"current_period_used":2,"current_period_spend":50,"period_ends_date":"2021-10-10T10:02:00Z"},
+			"rule_violation":"high-frequency-small-interval"}]}
+	}`
+)
+
+func TestAccountRepository_Retrieve_Historical_Versions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		record string
+	}{
+		{name: "carregando uma conta na versão 1", record: accountV1},
+		{name: "carregando uma conta na versão 1 sem transações", record: accountV1WithoutTransactions},
+		{name: "carregando uma conta na versão 2", record: accountV2},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewInMemoryDB()
+			_ = db.Insert("accounts", domain.DefaultAccountID, json.RawMessage(tt.record))
+
+			account, err := NewAccountRepository(db, NewAuthorizationRepository(), clock.NewSystemClock()).Retrieve(time.Date(2021, 10, 10, 10, 1, 0, 0, time.UTC))
+
+			assert.NoError(t, err)
+			assert.Equal(t, buildAccount(), *account)
+		})
+	}
+}
+
+func TestAccountRepository_Retrieve_Unsupported_Version(t *testing.T) {
+	for _, record := range []string{`{"schema_version":3}`, `{"schema_version":"2"}`, `{"schema_version":0}`} {
+		db := database.NewInMemoryDB()
+		_ = db.Insert("accounts", domain.DefaultAccountID, json.RawMessage(record))
+
+		account, err := NewAccountRepository(db, NewAuthorizationRepository(), clock.NewSystemClock()).Retrieve(time.Now())
+
+		assert.Nil(t, account)
+		assert.Error(t, err)
+	}
+}
+
+func TestAccountRepository_Writes_Current_Version(t *testing.T) {
+	db := database.NewInMemoryDB()
+	_ = NewAccountRepository(db, NewAuthorizationRepository(), clock.NewSystemClock()).Create(buildAccount())
+
+	var record map[string]interface{}
+	_ = db.Find("accounts", domain.DefaultAccountID, &record)
+
+	assert.Equal(t, float64(currentSchemaVersion), record["schema_version"])
+}
+
+func TestUpgrades_Cover_Every_Version(t *testing.T) {
+	for version := 1; version < currentSchemaVersion; version++ {
+		_, exists := upgrades[version]
+		assert.True(t, exists, "missing upgrade from schema version %d", version)
+	}
+}
+
+func TestAccountRepository_Retrieve_Migrates_V1_History(t *testing.T) {
+	db := database.NewInMemoryDB()
+	_ = db.Insert("accounts", domain.DefaultAccountID, json.RawMessage(accountV1))
+
+	history := NewAuthorizationRepository()
+	ar := NewAccountRepository(db, history, clock.NewSystemClock())
+	currentTime := time.Date(2021, 10, 10, 10, 1, 0, 0, time.UTC)
+
+	_, err := ar.Retrieve(currentTime)
+	assert.NoError(t, err)
+
+	_, err = ar.Retrieve(currentTime)
+	assert.NoError(t, err)
+
+	page, _ := history.List(ports.AuthorizationQuery{})
+	assert.Equal(t, []domain.TransactionAuthorization{
+		{
+			ID:             "v1-1",
+			Merchant:       "Merchant1",
+			Amount:         50,
+			AvailableLimit: 500,
+			Time:           time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC),
+			Violations:     domain.Violations{},
+		},
+	}, page.Authorizations, "the history is migrated once")
+
+	nearest, err := history.FindNearest("Merchant1", 50, currentTime)
+	assert.NoError(t, err)
+	assert.Equal(t, "Merchant1", nearest.Merchant, "migrated authorizations keep serving the doubled-transaction check")
+
+	var record map[string]interface{}
+	_ = db.Find("accounts", domain.DefaultAccountID, &record)
+
+	assert.Equal(t, float64(currentSchemaVersion), record["schema_version"])
+	assert.NotContains(t, record, "transactions")
+}
+
+func TestAccountRepository_Retrieve_Skips_Migrated_Authorizations(t *testing.T) {
+	history := NewAuthorizationRepository()
+
+	// the record is still at version 1 after its history was moved, as when the upgrade stopped
+	// before storing it back
+	for i := 0; i < 2; i++ {
+		db := database.NewInMemoryDB()
+		_ = db.Insert("accounts", domain.DefaultAccountID, json.RawMessage(accountV1))
+
+		_, err := NewAccountRepository(db, history, clock.NewSystemClock()).Retrieve(time.Now())
+		assert.NoError(t, err)
+	}
+
+	page, _ := history.List(ports.AuthorizationQuery{})
+	assert.Equal(t, 1, page.Total)
+}
+
+func TestAccountRepository_Retrieve_Keeps_Int64_Precision(t *testing.T) {
+	const limit = int64(1<<53 + 1)
+
+	testCases := []struct {
+		name   string
+		record string
+	}{
+		{
+			name:   "conta na versão 1",
+			record: `{"ledger":{"active":true,"max_limit":9007199254740993,"available_limit":9007199254740993},"spending_control":{"rules":[]}}`,
+		},
+		{
+			name:   "conta na versão 2",
+			record: `{"schema_version":2,"ledger":{"active":true,"max_limit":9007199254740993,"available_limit":9007199254740993},"spending_control":{"rules":[]}}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			db := database.NewInMemoryDB()
+			_ = db.Insert("accounts", domain.DefaultAccountID, json.RawMessage(tt.record))
+
+			account, err := NewAccountRepository(db, NewAuthorizationRepository(), clock.NewSystemClock()).Retrieve(time.Now())
+
+			assert.NoError(t, err)
+			assert.Equal(t, limit, account.Ledger.MaxLimit)
+			assert.Equal(t, limit, account.Ledger.AvailableLimit)
+		})
+	}
+}
@@ -1,55 +1,185 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
 	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/logging"
 	"github.com/authorizer/internal/dto"
 	"io"
+	"strings"
+	"time"
 )
 
 type Handler struct {
 	accountService     service.Account
 	transactionService service.Transaction
+	idempotency        service.Idempotency
+	verbose            bool
+	logger             *logging.Logger
+	reorder            time.Duration
 }
 
-func NewHandler(as service.Account, ts service.Transaction) Handler {
-	return Handler{accountService: as, transactionService: ts}
+func NewHandler(as service.Account, ts service.Transaction, is service.Idempotency) Handler {
+	return Handler{accountService: as, transactionService: ts, idempotency: is}
 }
 
+// NewVerboseHandler create a new Handler instance that explains every check behind
+// each authorization
+func NewVerboseHandler(as service.Account, ts service.Transaction, is service.Idempotency) Handler {
+	return Handler{accountService: as, transactionService: ts, idempotency: is, verbose: true}
+}
+
+// WithLogger return a copy of the handler reporting the failures it still answers, such as
+// a response the idempotency store could not record, to the logger. Without one they are dropped
+func (h Handler) WithLogger(logger *logging.Logger) Handler {
+	h.logger = logger
+	return h
+}
+
+// WithReorder return a copy of the handler whose Handle holds transactions and processes them
+// in time order as HandleReordered does, zero processes every line as it comes
+func (h Handler) WithReorder(window time.Duration) Handler {
+	h.reorder = window
+	return h
+}
+
+// Handle reads one operation per line until the input ends, lines that cannot be
+// decoded are answered with a dto.InputError and do not stop the processing
 func (h Handler) Handle(r io.Reader, w io.Writer) error {
-	decoder := json.NewDecoder(r)
-	decoder.UseNumber()
+	if h.reorder > 0 {
+		return h.HandleReordered(r, w, h.reorder)
+	}
 
-	for {
-		var input dto.Input
+	return readLines(r, func(lineNumber int, line []byte) error {
+		return writeJSON(w, h.handleLine(lineNumber, line))
+	})
+}
+
+// readLines hand every line that is not blank to fn, with its number in the input, until
+// the input ends or fn fails
+func readLines(r io.Reader, fn func(lineNumber int, line []byte) error) error {
+	reader := bufio.NewReader(r)
 
-		err := decoder.Decode(&input)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadBytes('\n')
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			if ferr := fn(lineNumber, line); ferr != nil {
+				return ferr
+			}
+		}
 
 		if err == io.EOF {
-			break
+			return nil
 		}
 
-		resp := h.handle(input)
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+	}
+}
+
+func (h Handler) handleLine(lineNumber int, line []byte) interface{} {
+	answer, _ := h.answerLine(lineNumber, line)
+	return answer
+}
+
+// answerLine answer the line and tell if the answer is the response recorded for an earlier
+// line sent with the same idempotency key, the operation did not run again then
+func (h Handler) answerLine(lineNumber int, line []byte) (answer interface{}, replayed bool) {
+	var input dto.Input
 
-		jm, _ := json.Marshal(resp)
+	if err := json.Unmarshal(line, &input); err != nil {
+		return dto.InputError{Line: lineNumber, Error: decodeReason(err)}, false
+	}
 
-		_, _ = fmt.Fprintln(w, string(jm))
+	if input.IdempotencyKey != "" {
+		return h.handleIdempotent(input)
 	}
 
-	return nil
+	return h.handle(input), false
 }
 
-func (h Handler) handle(input dto.Input) dto.Output {
+// handleIdempotent answer the operation once per key, retries get the recorded response
+// byte for byte and are reported as replayed
+func (h Handler) handleIdempotent(input dto.Input) (interface{}, bool) {
+	key := input.IdempotencyKey
+
+	input.IdempotencyKey = ""
+	payload, _ := json.Marshal(input)
+	fingerprint := sha256.Sum256(payload)
+
+	ran := false
+
+	response, violations, err := h.idempotency.Execute(key, hex.EncodeToString(fingerprint[:]), func() ([]byte, bool) {
+		ran = true
+		output := h.handle(input)
+		jm, _ := json.Marshal(output)
+
+		return jm, output.Error == ""
+	})
+
+	if errors.Is(err, ports.ErrResponseNotRecorded) {
+		// the operation ran, so its outcome is answered rather than an error that would
+		// invite a retry applying it again
+		h.logger.Error("idempotent response not recorded", logging.Field{Key: "idempotency-key", Value: key}, logging.Field{Key: "error", Value: err})
+	} else if err != nil {
+		return dto.NewErrorOutput(err), false
+	}
+
+	if len(violations) > 0 {
+		return dto.NewOutput(nil, violations), false
+	}
+
+	return json.RawMessage(response), !ran
+}
+
+func decodeReason(err error) string {
 	var (
-		account    *domain.Account
-		violations []string
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		timeErr   *time.ParseError
 	)
 
+	if errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset)
+	}
+
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("unexpected %s value", typeErr.Value)
+	}
+
+	if errors.As(err, &timeErr) {
+		return fmt.Sprintf("invalid time %q", strings.Trim(timeErr.Value, `"`))
+	}
+
+	return "malformed JSON"
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	jm, _ := json.Marshal(v)
+
+	_, err := fmt.Fprintln(w, string(jm))
+
+	return err
+}
+
+func (h Handler) handle(input dto.Input) dto.Output {
 	if input.Account != nil {
-		account, violations = h.accountService.InitAccount(input.Account.ActiveCard, input.Account.AvailableLimit)
-		return buildOutput(account, violations)
+		account, violations, err := h.accountService.InitAccount(input.Account.ActiveCard, input.Account.AvailableLimit)
+		if err != nil {
+			return dto.NewErrorOutput(err)
+		}
+
+		return dto.NewOutput(account, violations)
 	}
 
 	if input.Transaction != nil {
@@ -59,24 +189,57 @@ func (h Handler) handle(input dto.Input) dto.Output {
 			Time:     input.Transaction.Time,
 		}
 
-		account, violations = h.transactionService.Authorize(t)
-		return buildOutput(account, violations)
+		decision, err := h.transactionService.Authorize(t)
+		if err != nil {
+			return dto.NewErrorOutput(err)
+		}
+
+		return dto.NewDecisionOutput(decision, h.verbose)
 	}
 
-	return dto.Output{}
-}
+	if input.GetAuthorization != nil {
+		authorization, violations, err := h.transactionService.GetAuthorization(input.GetAuthorization.ID)
+		if err != nil {
+			return dto.NewErrorOutput(err)
+		}
 
-func buildOutput(account *domain.Account, violations []string) dto.Output {
-	output := dto.Output{
-		Violations: violations,
+		return dto.NewAuthorizationOutput(authorization, violations)
 	}
 
-	if account != nil {
-		output.Account = dto.AccountOutput{
-			ActiveCard:     &account.Ledger.ActiveCard,
-			AvailableLimit: &account.Ledger.AvailableLimit,
+	if input.GetAccount != nil {
+		var (
+			account    *domain.Account
+			violations []string
+			err        error
+		)
+
+		if input.GetAccount.At.IsZero() {
+			account, violations, err = h.accountService.GetAccount()
+		} else {
+			account, violations, err = h.accountService.GetAccountAt(input.GetAccount.At)
 		}
+
+		if err != nil {
+			return dto.NewErrorOutput(err)
+		}
+
+		return dto.NewAccountViewOutput(account, violations)
+	}
+
+	if input.ListAuthorizations != nil {
+		page, violations, err := h.transactionService.ListAuthorizations(ports.AuthorizationQuery{
+			From:     input.ListAuthorizations.From,
+			To:       input.ListAuthorizations.To,
+			Merchant: input.ListAuthorizations.Merchant,
+			Offset:   input.ListAuthorizations.Offset,
+			Limit:    input.ListAuthorizations.Limit,
+		})
+		if err != nil {
+			return dto.NewErrorOutput(err)
+		}
+
+		return dto.NewHistoryOutput(page, violations)
 	}
 
-	return output
+	return dto.NewOutput(nil, []string{domain.InvalidOperationViolation})
 }
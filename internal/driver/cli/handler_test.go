@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/authorizer/internal/core/domain"
 	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
 	"github.com/authorizer/internal/driven/database"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
 	"github.com/authorizer/internal/driven/repository"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,37 +42,73 @@ func TestHandler_Handle(t *testing.T) {
 		{
 			name:     "Processando uma transação com sucesso",
 			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}",
-			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[]}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n",
 		},
 		{
 			name:     "Processando uma transação que viola a lógica account-not-initialized",
 			input:    "{\"transaction\":{\"merchant\":\"Uber Eats\",\"amount\":25,\"time\":\"2020-12-01T11:07:00.000Z\"}}\n{\"account\":{\"active-card\":true,\"available-limit\":225}}\n{\"transaction\":{\"merchant\":\"Uber Eats\",\"amount\":25,\"time\":\"2020-12-01T11:07:00.000Z\"}}\n",
-			expected: "{\"account\":{},\"violations\":[\"account-not-initialized\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":225},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":200},\"violations\":[]}\n",
+			expected: "{\"account\":{},\"violations\":[\"account-not-initialized\"],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":true,\"available-limit\":225},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":200},\"violations\":[],\"authorization-id\":\"00000000000000000002\"}\n",
 		},
 		{
 			name:     "Processando uma transação que viola a lógica card-not-active",
 			input:    "{\"account\":{\"active-card\":false,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":15,\"time\":\"2019-02-13T11:15:00.000Z\"}}\n",
-			expected: "{\"account\":{\"active-card\":false,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":false,\"available-limit\":100},\"violations\":[\"card-not-active\"]}\n{\"account\":{\"active-card\":false,\"available-limit\":100},\"violations\":[\"card-not-active\"]}\n",
+			expected: "{\"account\":{\"active-card\":false,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":false,\"available-limit\":100},\"violations\":[\"card-not-active\"],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":false,\"available-limit\":100},\"violations\":[\"card-not-active\"],\"authorization-id\":\"00000000000000000002\"}\n",
 		},
 		{
 			name:     "Processando uma transação que viola a lógica insufficient-limit",
 			input:    "{\"account\":{\"active-card\":true,\"available-limit\":1000}}\n{\"transaction\":{\"merchant\":\"Vivara\",\"amount\":1250,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"Samsung\",\"amount\":2500,\"time\":\"2019-02-13T11:00:01.000Z\"}}\n{\"transaction\":{\"merchant\":\"Nike\",\"amount\":800,\"time\":\"2019-02-13T11:01:01.000Z\"}}\n",
-			expected: "{\"account\":{\"active-card\":true,\"available-limit\":1000},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":1000},\"violations\":[\"insufficient-limit\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":1000},\"violations\":[\"insufficient-limit\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":200},\"violations\":[]}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":1000},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":1000},\"violations\":[\"insufficient-limit\"],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":true,\"available-limit\":1000},\"violations\":[\"insufficient-limit\"],\"authorization-id\":\"00000000000000000002\"}\n{\"account\":{\"active-card\":true,\"available-limit\":200},\"violations\":[],\"authorization-id\":\"00000000000000000003\"}\n",
 		},
 		{
 			name:     "Processando uma transação que viola a lógica high-frequency-small-interval",
 			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":20,\"time\":\"2019-02-13T11:00:01.000Z\"}}\n{\"transaction\":{\"merchant\":\"McDonald's\",\"amount\":20,\"time\":\"2019-02-13T11:01:01.000Z\"}}\n{\"transaction\":{\"merchant\":\"Subway\",\"amount\":20,\"time\":\"2019-02-13T11:01:31.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":10,\"time\":\"2019-02-13T12:00:00.000Z\"}}\n",
-			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":60},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":40},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":40},\"violations\":[\"high-frequency-small-interval\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":30},\"violations\":[]}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":true,\"available-limit\":60},\"violations\":[],\"authorization-id\":\"00000000000000000002\"}\n{\"account\":{\"active-card\":true,\"available-limit\":40},\"violations\":[],\"authorization-id\":\"00000000000000000003\"}\n{\"account\":{\"active-card\":true,\"available-limit\":40},\"violations\":[\"high-frequency-small-interval\"],\"authorization-id\":\"00000000000000000004\"}\n{\"account\":{\"active-card\":true,\"available-limit\":30},\"violations\":[],\"authorization-id\":\"00000000000000000005\"}\n",
 		},
 		{
 			name:     "Processando uma transação que viola a lógica doubled-transaction",
 			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"McDonald's\",\"amount\":10,\"time\":\"2019-02-13T11:00:01.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:02.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":15,\"time\":\"2019-02-13T11:00:03.000Z\"}}\n",
-			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[\"doubled-transaction\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":55},\"violations\":[]}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[],\"authorization-id\":\"00000000000000000002\"}\n{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[\"doubled-transaction\"],\"authorization-id\":\"00000000000000000003\"}\n{\"account\":{\"active-card\":true,\"available-limit\":55},\"violations\":[],\"authorization-id\":\"00000000000000000004\"}\n",
 		},
 		{
 			name:     "Processando transações que violam multiplas lógicas",
 			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"McDonald's\",\"amount\":10,\"time\":\"2019-02-13T11:00:01.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:02.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":5,\"time\":\"2019-02-13T11:00:07.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":5,\"time\":\"2019-02-13T11:00:08.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":150,\"time\":\"2019-02-13T11:00:18.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":190,\"time\":\"2019-02-13T11:00:22.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":15,\"time\":\"2019-02-13T12:00:27.000Z\"}}\n",
-			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":90},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[\"high-frequency-small-interval\",\"doubled-transaction\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[\"insufficient-limit\",\"high-frequency-small-interval\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[\"insufficient-limit\",\"high-frequency-small-interval\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":50},\"violations\":[]}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":90},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[],\"authorization-id\":\"00000000000000000002\"}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[],\"authorization-id\":\"00000000000000000003\"}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[\"high-frequency-small-interval\",\"doubled-transaction\"],\"authorization-id\":\"00000000000000000004\"}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[\"insufficient-limit\",\"high-frequency-small-interval\"],\"authorization-id\":\"00000000000000000005\"}\n{\"account\":{\"active-card\":true,\"available-limit\":65},\"violations\":[\"insufficient-limit\",\"high-frequency-small-interval\"],\"authorization-id\":\"00000000000000000006\"}\n{\"account\":{\"active-card\":true,\"available-limit\":50},\"violations\":[],\"authorization-id\":\"00000000000000000007\"}\n",
+		},
+		{
+			name:     "Processando linhas mal formadas sem interromper a leitura",
+			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\n\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":\"vinte\",\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"ontem\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"line\":2,\"error\":\"malformed JSON at offset 54\"}\n{\"line\":4,\"error\":\"unexpected string value\"}\n{\"line\":5,\"error\":\"invalid time \\\"ontem\\\"\"}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n",
+		},
+		{
+			name:     "Processando operações com dados inválidos",
+			input:    "{\"account\":{\"active-card\":true,\"available-limit\":-10}}\n{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":0,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"\",\"amount\":20}}\n",
+			expected: "{\"account\":{},\"violations\":[\"invalid-limit\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{},\"violations\":[\"invalid-amount\"]}\n{\"account\":{},\"violations\":[\"invalid-merchant\",\"invalid-time\"]}\n",
+		},
+		{
+			name:     "Consultando autorizações pelo identificador",
+			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Vivara\",\"amount\":1250,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"get-authorization\":{\"id\":\"00000000000000000001\"}}\n{\"get-authorization\":{\"id\":\"00000000000000000002\"}}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[\"insufficient-limit\"],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{},\"authorization\":{\"id\":\"00000000000000000001\",\"merchant\":\"Vivara\",\"amount\":1250,\"time\":\"2019-02-13T11:00:00Z\",\"available-limit\":100,\"violations\":[\"insufficient-limit\"]},\"violations\":[]}\n{\"account\":{},\"violations\":[\"authorization-not-found\"]}\n",
+		},
+		{
+			name:     "Repetindo operações com chave de idempotência",
+			input:    "{\"idempotency-key\":\"conta\",\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"idempotency-key\":\"t1\",\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"idempotency-key\":\"t1\",\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"idempotency-key\":\"t1\",\"transaction\":{\"merchant\":\"Burger King\",\"amount\":25,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"idempotency-key\":\"conta\",\"account\":{\"active-card\":true,\"available-limit\":100}}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n{\"account\":{},\"violations\":[\"idempotency-key-reused\"]}\n{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n",
+		},
+		{
+			name:  "Consultando a conta e o histórico",
+			input: "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":90,\"time\":\"2019-02-13T11:00:10.000Z\"}}\n{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":10,\"time\":\"2019-02-13T11:00:20.000Z\"}}\n{\"get-account\":{\"at\":\"2019-02-13T11:00:30.000Z\"}}\n{\"list-authorizations\":{\"merchant\":\"Burger King\",\"limit\":1,\"offset\":1}}\n{\"list-authorizations\":{\"offset\":-1}}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n" +
+				"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n" +
+				"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[\"insufficient-limit\"],\"authorization-id\":\"00000000000000000002\"}\n" +
+				"{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[],\"authorization-id\":\"00000000000000000003\"}\n" +
+				"{\"account\":{\"active-card\":true,\"available-limit\":70,\"max-limit\":100,\"rules\":[{\"name\":\"max transactions in 2 minutes\",\"type\":\"usage-limit\",\"usage-limit\":3,\"period-used\":2,\"period-spend\":30,\"period-ends\":\"2019-02-13T11:02:00Z\",\"remaining\":1}]},\"violations\":[]}\n" +
+				"{\"account\":{},\"history\":{\"authorizations\":[{\"id\":\"00000000000000000003\",\"merchant\":\"Burger King\",\"amount\":10,\"time\":\"2019-02-13T11:00:20Z\",\"available-limit\":80,\"violations\":[]}],\"total\":2,\"offset\":1,\"limit\":1},\"violations\":[]}\n" +
+				"{\"account\":{},\"violations\":[\"invalid-query\"]}\n",
+		},
+		{
+			name:     "Processando uma operação desconhecida",
+			input:    "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n{\"refund\":{\"amount\":20}}\n{}\n",
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n{\"account\":{},\"violations\":[\"invalid-operation\"]}\n{\"account\":{},\"violations\":[\"invalid-operation\"]}\n",
 		},
 	}
 
@@ -78,15 +119,90 @@ func TestHandler_Handle(t *testing.T) {
 
 			db := database.NewInMemoryDB()
 
-			accountRepo := repository.NewAccountRepository(db)
+			authorizationRepo := repository.NewAuthorizationRepository()
+			accountRepo := repository.NewAccountRepository(db, authorizationRepo, clock.NewSystemClock())
+			locker := lock.NewInMemoryLocker()
 
-			as := service.NewAccount(accountRepo)
-			ts := service.NewTransaction(accountRepo)
+			as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+			ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), repository.NewOutboxRepository(db, clock.NewSystemClock()), domain.RetentionPolicy{})
+			is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
 
-			handler := NewHandler(as, ts)
+			handler := NewHandler(as, ts, is)
 
 			_ = handler.Handle(stdin, &stdout)
 			assert.Equal(t, tt.expected, stdout.String())
 		})
 	}
 }
+
+func TestVerboseHandler_Handle(t *testing.T) {
+	var stdout bytes.Buffer
+
+	stdin := strings.NewReader("{\"account\":{\"active-card\":true,\"available-limit\":10}}\n{\"transaction\":{\"merchant\":\"Vivara\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n")
+
+	db := database.NewInMemoryDB()
+	authorizationRepo := repository.NewAuthorizationRepository()
+	accountRepo := repository.NewAccountRepository(db, authorizationRepo, clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), repository.NewOutboxRepository(db, clock.NewSystemClock()), domain.RetentionPolicy{})
+	is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+	err := NewVerboseHandler(as, ts, is).Handle(stdin, &stdout)
+
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+
+	assert.Len(t, lines, 2)
+	assert.NotContains(t, lines[0], "checks")
+	assert.Contains(t, lines[1], "{\"name\":\"ledger\",\"result\":\"failed\",\"violation\":\"insufficient-limit\",\"details\":{\"amount\":20,\"available-limit\":10,\"remaining\":-10}}")
+}
+
+func TestHandler_Handle_Default_Retention(t *testing.T) {
+	input := "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+		"{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+		"{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":20,\"time\":\"2019-02-13T11:05:00.000Z\"}}\n" +
+		"{\"get-authorization\":{\"id\":\"00000000000000000001\"}}\n"
+
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{MaxAge: domain.DefaultRetention})
+	is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+	var stdout bytes.Buffer
+
+	_ = NewHandler(as, ts, is).Handle(strings.NewReader(input), &stdout)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+
+	assert.Equal(t, "{\"account\":{},\"authorization\":{\"id\":\"00000000000000000001\",\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00Z\",\"available-limit\":100,\"violations\":[]},\"violations\":[]}", lines[3],
+		"authorizations older than the doubled-transaction window can still be looked up")
+}
+
+func TestHandler_Handle_Default_Retention_Lists_History(t *testing.T) {
+	input := "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+		"{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+		"{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":20,\"time\":\"2019-02-13T11:05:00.000Z\"}}\n" +
+		"{\"list-authorizations\":{}}\n"
+
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{MaxAge: domain.DefaultRetention})
+	is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+	var stdout bytes.Buffer
+
+	_ = NewHandler(as, ts, is).Handle(strings.NewReader(input), &stdout)
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+
+	assert.Contains(t, lines[3], "\"total\":2", "entries older than the doubled-transaction window stay in the history")
+	assert.Contains(t, lines[3], "\"id\":\"00000000000000000001\"")
+	assert.Contains(t, lines[3], "\"id\":\"00000000000000000002\"")
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/authorizer/internal/dto"
+)
+
+// heldLine is an input line waiting to be processed, seq is its position among the answered lines
+type heldLine struct {
+	seq        int
+	lineNumber int
+	line       []byte
+	time       time.Time
+}
+
+// reorderBuffer holds transactions until they are window behind the latest transaction time
+// read, then processes them oldest first. Outputs are kept until every earlier line is answered
+type reorderBuffer struct {
+	handler  Handler
+	window   time.Duration
+	w        io.Writer
+	held     []heldLine
+	latest   time.Time
+	outputs  map[int]interface{}
+	next     int
+	received int
+}
+
+// HandleReordered reads one operation per line like Handle, but holds transactions until the
+// input reaches window past their time and processes them in time order. Any other operation,
+// and the end of the input, first processes everything held. Outputs keep the input order
+func (h Handler) HandleReordered(r io.Reader, w io.Writer, window time.Duration) error {
+	b := &reorderBuffer{handler: h, window: window, w: w, outputs: make(map[int]interface{})}
+
+	err := readLines(r, func(lineNumber int, line []byte) error {
+		return b.add(lineNumber, line)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return b.release(time.Time{}, true)
+}
+
+// add hold the line when it is a transaction, otherwise process everything held and the line
+func (b *reorderBuffer) add(lineNumber int, line []byte) error {
+	held := heldLine{seq: b.received, lineNumber: lineNumber, line: line}
+	b.received++
+
+	var input dto.Input
+
+	if err := json.Unmarshal(line, &input); err != nil || input.Transaction == nil || input.Transaction.Time.IsZero() {
+		if err := b.release(time.Time{}, true); err != nil {
+			return err
+		}
+
+		return b.process(held)
+	}
+
+	held.time = input.Transaction.Time
+	b.held = append(b.held, held)
+
+	if held.time.After(b.latest) {
+		b.latest = held.time
+	}
+
+	return b.release(b.latest.Add(-b.window), false)
+}
+
+// release process the held transactions up to until, or all of them, oldest first
+func (b *reorderBuffer) release(until time.Time, all bool) error {
+	sort.SliceStable(b.held, func(i, j int) bool {
+		return b.held[i].time.Before(b.held[j].time)
+	})
+
+	n := len(b.held)
+	if !all {
+		n = sort.Search(len(b.held), func(i int) bool {
+			return b.held[i].time.After(until)
+		})
+	}
+
+	ready := b.held[:n]
+	b.held = append([]heldLine(nil), b.held[n:]...)
+
+	for _, held := range ready {
+		if err := b.process(held); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// process answer the line and write every output whose earlier lines are all answered
+func (b *reorderBuffer) process(held heldLine) error {
+	b.outputs[held.seq] = b.handler.handleLine(held.lineNumber, held.line)
+
+	for {
+		output, exists := b.outputs[b.next]
+		if !exists {
+			return nil
+		}
+
+		if err := writeJSON(b.w, output); err != nil {
+			return err
+		}
+
+		delete(b.outputs, b.next)
+		b.next++
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Handle_Reordered(t *testing.T) {
+	input := "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+		"{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:30.000Z\"}}\n" +
+		"{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":30,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+		"{\"transaction\":{\"merchant\":\"Subway\",\"amount\":10,\"time\":\"2019-02-13T11:02:00.000Z\"}}\n" +
+		"not json\n" +
+		"{\"transaction\":{\"merchant\":\"McDonald's\",\"amount\":10,\"time\":\"2019-02-13T11:01:00.000Z\"}}\n"
+
+	testCases := []struct {
+		name          string
+		window        time.Duration
+		expectedLines []string
+	}{
+		{
+			name:   "reordenando as transações dentro da janela",
+			window: time.Minute,
+			expectedLines: []string{
+				"{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":50},\"violations\":[],\"authorization-id\":\"00000000000000000002\"}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":40},\"violations\":[],\"authorization-id\":\"00000000000000000003\"}",
+				"{\"line\":5,\"error\":\"malformed JSON at offset 2\"}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":40},\"violations\":[\"transaction-out-of-order\"],\"authorization-id\":\"00000000000000000004\"}",
+			},
+		},
+		{
+			name:   "sem janela as transações seguem a ordem de chegada",
+			window: 0,
+			expectedLines: []string{
+				"{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[\"transaction-out-of-order\"],\"authorization-id\":\"00000000000000000002\"}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[],\"authorization-id\":\"00000000000000000003\"}",
+				"{\"line\":5,\"error\":\"malformed JSON at offset 2\"}",
+				"{\"account\":{\"active-card\":true,\"available-limit\":70},\"violations\":[\"transaction-out-of-order\"],\"authorization-id\":\"00000000000000000004\"}",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+			locker := lock.NewInMemoryLocker()
+
+			as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+			ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+			is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+			var output bytes.Buffer
+
+			err := NewHandler(as, ts, is).WithReorder(tt.window).Handle(strings.NewReader(input), &output)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLines, strings.Split(strings.TrimSpace(output.String()), "\n"))
+		})
+	}
+}
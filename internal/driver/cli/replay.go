@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+
+	"github.com/authorizer/internal/dto"
+)
+
+// Difference is an input line whose output is not the expected one
+type Difference struct {
+	Line     int             `json:"line"`
+	Input    json.RawMessage `json:"input"`
+	Expected json.RawMessage `json:"expected"`
+	Actual   json.RawMessage `json:"actual"`
+}
+
+// Replay run every input line through the handler and compare its output with the expected
+// line at the same position, blank lines aside. Authorization IDs are left out of the
+// comparison at any depth since every run gives new ones, and each recorded ID is mapped to
+// the one given in its place, so a lookup of a recorded ID asks for the replayed authorization.
+// It returns how many lines ran and the lines that differ, in input order
+func (h Handler) Replay(input io.Reader, expected io.Reader) (int, []Difference, error) {
+	var expectedLines [][]byte
+
+	err := readLines(expected, func(_ int, line []byte) error {
+		expectedLines = append(expectedLines, bytes.TrimSpace(line))
+		return nil
+	})
+
+	if err != nil {
+		return 0, nil, err
+	}
+
+	replayed := 0
+	differences := make([]Difference, 0)
+	ids := make(map[string]string)
+
+	err = readLines(input, func(lineNumber int, line []byte) error {
+		actual, _ := json.Marshal(h.handleLine(lineNumber, replayedIDs(line, ids)))
+
+		var want []byte
+		if replayed < len(expectedLines) {
+			want = expectedLines[replayed]
+		}
+
+		replayed++
+
+		if sameDecision(want, actual, ids) {
+			return nil
+		}
+
+		differences = append(differences, Difference{
+			Line:     lineNumber,
+			Input:    rawJSON(bytes.TrimSpace(line)),
+			Expected: rawJSON(want),
+			Actual:   actual,
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return replayed, nil, err
+	}
+
+	return replayed, differences, nil
+}
+
+// sameDecision compare two outputs as JSON values without their authorization IDs, the IDs
+// found at the same place in both are added to ids
+func sameDecision(expected, actual []byte, ids map[string]string) bool {
+	var e, a interface{}
+
+	if json.Unmarshal(expected, &e) != nil || json.Unmarshal(actual, &a) != nil {
+		return bytes.Equal(expected, actual)
+	}
+
+	matchIDs(e, a, ids)
+	stripIDs(e)
+	stripIDs(a)
+
+	return reflect.DeepEqual(e, a)
+}
+
+// idKeys name the fields holding an authorization ID, at the top of a decision and in the
+// authorizations of a lookup or a history page
+var idKeys = []string{"authorization-id", "id"}
+
+// matchIDs record in ids the expected authorization IDs with the actual ones found at the same
+// place, at every depth
+func matchIDs(expected, actual interface{}, ids map[string]string) {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, _ := actual.(map[string]interface{})
+
+		for _, key := range idKeys {
+			expectedID, expectedOK := e[key].(string)
+			actualID, actualOK := a[key].(string)
+
+			if expectedOK && actualOK {
+				ids[expectedID] = actualID
+			}
+		}
+
+		for key, value := range e {
+			matchIDs(value, a[key], ids)
+		}
+	case []interface{}:
+		a, _ := actual.([]interface{})
+
+		for i := 0; i < len(e) && i < len(a); i++ {
+			matchIDs(e[i], a[i], ids)
+		}
+	}
+}
+
+// stripIDs remove the authorization IDs at every depth of the value
+func stripIDs(v interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for _, key := range idKeys {
+			delete(value, key)
+		}
+
+		for _, field := range value {
+			stripIDs(field)
+		}
+	case []interface{}:
+		for _, item := range value {
+			stripIDs(item)
+		}
+	}
+}
+
+// replayedIDs rewrite the ID a get-authorization line asks for to the one given in its place
+// in this run, other lines are left as they are
+func replayedIDs(line []byte, ids map[string]string) []byte {
+	var input dto.Input
+
+	if json.Unmarshal(line, &input) != nil || input.GetAuthorization == nil {
+		return line
+	}
+
+	id, ok := ids[input.GetAuthorization.ID]
+	if !ok {
+		return line
+	}
+
+	input.GetAuthorization = &dto.GetAuthorizationOperation{ID: id}
+	rewritten, _ := json.Marshal(input)
+
+	return rewritten
+}
+
+// rawJSON keep the line as it is when it holds JSON and quote it otherwise, a missing line is null
+func rawJSON(line []byte) json.RawMessage {
+	if line == nil {
+		return json.RawMessage("null")
+	}
+
+	if json.Valid(line) {
+		return line
+	}
+
+	quoted, _ := json.Marshal(string(line))
+
+	return quoted
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Replay(t *testing.T) {
+	input := "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+		"{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+		"\n" +
+		"{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":20,\"time\":\"2019-02-13T11:00:10.000Z\"}}\n"
+
+	recorded := "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n" +
+		"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"01FHMTA680ZZZZZZZZZZZZZZZZ\"}\n" +
+		"{\"account\":{\"active-card\":true,\"available-limit\":60},\"violations\":[],\"authorization-id\":\"01FHMTA6810000000000000000\"}\n"
+
+	strictRule := []domain.Rule{
+		{
+			Name:          "max transactions in 1 minute",
+			Type:          domain.UsageLimitRule,
+			UsageLimit:    1,
+			Accumulator:   &domain.Accumulator{Duration: time.Minute},
+			RuleViolation: "high-frequency-small-interval",
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		rules         []domain.Rule
+		recorded      string
+		expectedLines []int
+	}{
+		{
+			name:          "mesmas regras, diferindo só nos IDs",
+			rules:         domain.DefaultRules(),
+			recorded:      recorded,
+			expectedLines: []int{},
+		},
+		{
+			name:          "regra mais restritiva",
+			rules:         strictRule,
+			recorded:      recorded,
+			expectedLines: []int{4},
+		},
+		{
+			name:          "saída gravada incompleta",
+			rules:         domain.DefaultRules(),
+			recorded:      strings.SplitAfter(recorded, "\n")[0],
+			expectedLines: []int{2, 4},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+			locker := lock.NewInMemoryLocker()
+
+			as := service.NewAccountWithRules(accountRepo, locker, clock.NewSystemClock(), tt.rules)
+			ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+			is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+			replayed, differences, err := NewHandler(as, ts, is).Replay(strings.NewReader(input), strings.NewReader(tt.recorded))
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, replayed)
+
+			lines := []int{}
+			for _, difference := range differences {
+				lines = append(lines, difference.Line)
+			}
+
+			assert.Equal(t, tt.expectedLines, lines)
+		})
+	}
+}
+
+func TestHandler_Replay_Authorizations(t *testing.T) {
+	input := "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+		"{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n"
+
+	recorded := "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n" +
+		"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"01M5AHQ41TEGBA0EM55GH2N5Q7\"}\n"
+
+	testCases := []struct {
+		name          string
+		input         string
+		recorded      string
+		expectedLines []int
+	}{
+		{
+			name:          "consulta de uma autorização gravada",
+			input:         "{\"get-authorization\":{\"id\":\"01M5AHQ41TEGBA0EM55GH2N5Q7\"}}\n",
+			recorded:      "{\"account\":{},\"authorization\":{\"id\":\"01M5AHQ41TEGBA0EM55GH2N5Q7\",\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00Z\",\"available-limit\":100,\"violations\":[]},\"violations\":[]}\n",
+			expectedLines: []int{},
+		},
+		{
+			name:          "consulta de uma autorização que não foi gravada",
+			input:         "{\"get-authorization\":{\"id\":\"01M5AHQ41TEGBA0EM55GH2N5Q8\"}}\n",
+			recorded:      "{\"account\":{},\"authorization\":{\"id\":\"01M5AHQ41TEGBA0EM55GH2N5Q8\",\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00Z\",\"available-limit\":100,\"violations\":[]},\"violations\":[]}\n",
+			expectedLines: []int{3},
+		},
+		{
+			name:          "listagem das autorizações",
+			input:         "{\"list-authorizations\":{\"limit\":10}}\n",
+			recorded:      "{\"account\":{},\"history\":{\"authorizations\":[{\"id\":\"01M5AHQ41TEGBA0EM55GH2N5Q7\",\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00Z\",\"available-limit\":100,\"violations\":[]}],\"total\":1,\"offset\":0,\"limit\":10},\"violations\":[]}\n",
+			expectedLines: []int{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+			locker := lock.NewInMemoryLocker()
+
+			as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+			ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+			is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+			_, differences, err := NewHandler(as, ts, is).Replay(strings.NewReader(input+tt.input), strings.NewReader(recorded+tt.recorded))
+
+			assert.NoError(t, err)
+
+			lines := []int{}
+			for _, difference := range differences {
+				lines = append(lines, difference.Line)
+			}
+
+			assert.Equal(t, tt.expectedLines, lines)
+		})
+	}
+}
+
+func TestHandler_Replay_Difference(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+	is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+	_, differences, _ := NewHandler(as, ts, is).Replay(strings.NewReader("not json\n"), strings.NewReader(""))
+
+	assert.Equal(t, []Difference{
+		{
+			Line:     1,
+			Input:    []byte("\"not json\""),
+			Expected: []byte("null"),
+			Actual:   []byte("{\"line\":1,\"error\":\"malformed JSON at offset 2\"}"),
+		},
+	}, differences)
+}
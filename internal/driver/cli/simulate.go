@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/authorizer/internal/dto"
+)
+
+// Decisions a simulated transaction can get
+const (
+	ApprovedDecision = "approved"
+	DeclinedDecision = "declined"
+	ErrorDecision    = "error"
+)
+
+// Verdict is the decision on a transaction with the violations behind it
+type Verdict struct {
+	Decision   string   `json:"decision"`
+	Violations []string `json:"violations"`
+}
+
+// Outcome is how a transaction of the input was decided
+type Outcome struct {
+	Line        int                      `json:"line"`
+	Transaction dto.TransactionOperation `json:"transaction"`
+	Verdict
+}
+
+// Simulation sums up how the transactions of an input were decided. Transactions answered with
+// the response recorded for an earlier one sent with the same idempotency key were not decided
+// again, they are counted apart in IdempotentReplays
+type Simulation struct {
+	Transactions        int            `json:"transactions"`
+	IdempotentReplays   int            `json:"idempotent-replays"`
+	Approved            int            `json:"approved"`
+	ApprovalRate        float64        `json:"approval-rate"`
+	DeclinesByViolation map[string]int `json:"declines-by-violation"`
+	ApprovedAmount      int64          `json:"approved-amount"`
+	Outcomes            []Outcome      `json:"-"`
+}
+
+// Change is a transaction decided differently by two simulations, Before is missing when the
+// baseline did not see the transaction
+type Change struct {
+	Line        int                      `json:"line"`
+	Transaction dto.TransactionOperation `json:"transaction"`
+	Before      *Verdict                 `json:"before"`
+	After       Verdict                  `json:"after"`
+}
+
+// Simulate run every input line through the handler and sum up how the transactions were
+// decided. Other operations run too, so the account they create or query is the one the
+// transactions are decided against, but are not counted
+func (h Handler) Simulate(input io.Reader) (Simulation, error) {
+	simulation := Simulation{DeclinesByViolation: make(map[string]int), Outcomes: make([]Outcome, 0)}
+
+	err := readLines(input, func(lineNumber int, line []byte) error {
+		answer, replayed := h.answerLine(lineNumber, line)
+
+		var operation dto.Input
+		if json.Unmarshal(line, &operation) != nil || operation.Transaction == nil {
+			return nil
+		}
+
+		if replayed {
+			simulation.IdempotentReplays++
+			return nil
+		}
+
+		jm, _ := json.Marshal(answer)
+
+		var output dto.Output
+		_ = json.Unmarshal(jm, &output)
+
+		simulation.add(Outcome{
+			Line:        lineNumber,
+			Transaction: *operation.Transaction,
+			Verdict:     Verdict{Decision: decisionOf(output), Violations: nonNil(output.Violations)},
+		})
+
+		return nil
+	})
+
+	if err != nil {
+		return Simulation{}, err
+	}
+
+	if simulation.Transactions > 0 {
+		simulation.ApprovalRate = float64(simulation.Approved) / float64(simulation.Transactions)
+	}
+
+	return simulation, nil
+}
+
+func (s *Simulation) add(outcome Outcome) {
+	s.Transactions++
+	s.Outcomes = append(s.Outcomes, outcome)
+
+	switch outcome.Decision {
+	case ApprovedDecision:
+		s.Approved++
+		s.ApprovedAmount += outcome.Transaction.Amount
+	case DeclinedDecision:
+		for _, violation := range outcome.Violations {
+			s.DeclinesByViolation[violation]++
+		}
+	}
+}
+
+// Changes list the transactions the simulation decided differently from the baseline, run
+// over the same input, in input order. A transaction declined by both with other violations
+// counts as a change
+func (s Simulation) Changes(baseline Simulation) []Change {
+	before := make(map[int]Outcome, len(baseline.Outcomes))
+	for _, outcome := range baseline.Outcomes {
+		before[outcome.Line] = outcome
+	}
+
+	changes := make([]Change, 0)
+
+	for _, after := range s.Outcomes {
+		previous, ok := before[after.Line]
+		if ok && previous.Decision == after.Decision && sameViolations(previous.Violations, after.Violations) {
+			continue
+		}
+
+		change := Change{Line: after.Line, Transaction: after.Transaction, After: after.Verdict}
+
+		if ok {
+			change.Before = &previous.Verdict
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+func decisionOf(output dto.Output) string {
+	switch {
+	case output.Error != "":
+		return ErrorDecision
+	case len(output.Violations) == 0:
+		return ApprovedDecision
+	default:
+		return DeclinedDecision
+	}
+}
+
+func sameViolations(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nonNil(violations []string) []string {
+	if violations == nil {
+		return []string{}
+	}
+
+	return violations
+}
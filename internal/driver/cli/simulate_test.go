@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/authorizer/internal/dto"
+	"github.com/stretchr/testify/assert"
+)
+
+const simulationInput = "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+	"{\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+	"{\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":20,\"time\":\"2019-02-13T11:00:10.000Z\"}}\n" +
+	"{\"get-account\":{}}\n" +
+	"not json\n" +
+	"{\"transaction\":{\"merchant\":\"McDonald's\",\"amount\":70,\"time\":\"2019-02-13T11:00:20.000Z\"}}\n"
+
+var strictRules = []domain.Rule{
+	{
+		Name:          "max transactions in 1 minute",
+		Type:          domain.UsageLimitRule,
+		UsageLimit:    1,
+		Accumulator:   &domain.Accumulator{Duration: time.Minute},
+		RuleViolation: "high-frequency-small-interval",
+	},
+}
+
+func newSimulationHandler(rules []domain.Rule) Handler {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccountWithRules(accountRepo, locker, clock.NewSystemClock(), rules)
+	ts := service.NewTransaction(accountRepo, repository.NewAuthorizationRepository(), locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+	is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+	return NewHandler(as, ts, is)
+}
+
+func TestHandler_Simulate(t *testing.T) {
+	testCases := []struct {
+		name                string
+		rules               []domain.Rule
+		input               string
+		expectedApproved    int
+		expectedRate        float64
+		expectedDeclines    map[string]int
+		expectedAmount      int64
+		expectedTransaction int
+		expectedReplays     int
+	}{
+		{
+			name:                "regras padrão",
+			rules:               domain.DefaultRules(),
+			input:               simulationInput,
+			expectedApproved:    2,
+			expectedRate:        2.0 / 3,
+			expectedDeclines:    map[string]int{domain.InsufficientLimitViolation: 1},
+			expectedAmount:      40,
+			expectedTransaction: 3,
+		},
+		{
+			name:                "regra mais restritiva",
+			rules:               strictRules,
+			input:               simulationInput,
+			expectedApproved:    1,
+			expectedRate:        1.0 / 3,
+			expectedDeclines:    map[string]int{"high-frequency-small-interval": 2},
+			expectedAmount:      20,
+			expectedTransaction: 3,
+		},
+		{
+			name:                "sem transações",
+			rules:               domain.DefaultRules(),
+			input:               "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n",
+			expectedApproved:    0,
+			expectedRate:        0,
+			expectedDeclines:    map[string]int{},
+			expectedAmount:      0,
+			expectedTransaction: 0,
+		},
+		{
+			name:  "chave de idempotência repetida",
+			rules: domain.DefaultRules(),
+			input: "{\"account\":{\"active-card\":true,\"available-limit\":100}}\n" +
+				"{\"idempotency-key\":\"t1\",\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+				"{\"idempotency-key\":\"t1\",\"transaction\":{\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:00.000Z\"}}\n" +
+				"{\"idempotency-key\":\"t2\",\"transaction\":{\"merchant\":\"Habbib's\",\"amount\":20,\"time\":\"2019-02-13T11:00:10.000Z\"}}\n",
+			expectedApproved:    2,
+			expectedRate:        1,
+			expectedDeclines:    map[string]int{},
+			expectedAmount:      40,
+			expectedTransaction: 2,
+			expectedReplays:     1,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			simulation, err := newSimulationHandler(tt.rules).Simulate(strings.NewReader(tt.input))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedTransaction, simulation.Transactions)
+			assert.Equal(t, tt.expectedReplays, simulation.IdempotentReplays)
+			assert.Equal(t, tt.expectedApproved, simulation.Approved)
+			assert.InDelta(t, tt.expectedRate, simulation.ApprovalRate, 1e-9)
+			assert.Equal(t, tt.expectedDeclines, simulation.DeclinesByViolation)
+			assert.Equal(t, tt.expectedAmount, simulation.ApprovedAmount)
+		})
+	}
+}
+
+func TestSimulation_Changes(t *testing.T) {
+	baseline, err := newSimulationHandler(domain.DefaultRules()).Simulate(strings.NewReader(simulationInput))
+	assert.NoError(t, err)
+
+	candidate, err := newSimulationHandler(strictRules).Simulate(strings.NewReader(simulationInput))
+	assert.NoError(t, err)
+
+	assert.Empty(t, baseline.Changes(baseline))
+	assert.Equal(t, []Change{
+		{
+			Line:        3,
+			Transaction: dto.TransactionOperation{Merchant: "Habbib's", Amount: 20, Time: time.Date(2019, 2, 13, 11, 0, 10, 0, time.UTC)},
+			Before:      &Verdict{Decision: ApprovedDecision, Violations: []string{}},
+			After:       Verdict{Decision: DeclinedDecision, Violations: []string{"high-frequency-small-interval"}},
+		},
+		{
+			Line:        6,
+			Transaction: dto.TransactionOperation{Merchant: "McDonald's", Amount: 70, Time: time.Date(2019, 2, 13, 11, 0, 20, 0, time.UTC)},
+			Before:      &Verdict{Decision: DeclinedDecision, Violations: []string{domain.InsufficientLimitViolation}},
+			After:       Verdict{Decision: DeclinedDecision, Violations: []string{"high-frequency-small-interval"}},
+		},
+	}, candidate.Changes(baseline))
+}
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/dto"
+)
+
+// Handler exposes the authorizer over HTTP/JSON
+type Handler struct {
+	accountService     service.Account
+	transactionService service.Transaction
+	mux                *http.ServeMux
+}
+
+// NewHandler create a new Handler instance with its routes registered
+func NewHandler(as service.Account, ts service.Transaction) Handler {
+	h := Handler{accountService: as, transactionService: ts, mux: http.NewServeMux()}
+
+	h.mux.HandleFunc("/accounts", h.accounts)
+	h.mux.HandleFunc("/transactions", h.transactions)
+	h.mux.HandleFunc("/authorizations/", h.authorizations)
+
+	return h
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h Handler) accounts(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.createAccount(w, r)
+	case http.MethodGet:
+		h.getAccount(w)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost)
+	}
+}
+
+func (h Handler) transactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	var operation dto.TransactionOperation
+
+	if err := decodeBody(r, &operation); err != nil {
+		writeOutput(w, http.StatusBadRequest, dto.Output{Violations: []string{}, Error: err.Error()})
+		return
+	}
+
+	decision, err := h.transactionService.Authorize(domain.Transaction{
+		Merchant: operation.Merchant,
+		Amount:   operation.Amount,
+		Time:     operation.Time,
+	})
+
+	if err != nil {
+		writeOutput(w, http.StatusInternalServerError, dto.NewErrorOutput(err))
+		return
+	}
+
+	output := dto.NewDecisionOutput(decision, r.URL.Query().Get("verbose") == "true")
+
+	writeOutput(w, statusFor(decision.Violations, http.StatusOK), output)
+}
+
+func (h Handler) authorizations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/authorizations/")
+
+	authorization, violations, err := h.transactionService.GetAuthorization(id)
+
+	if err != nil {
+		writeOutput(w, http.StatusInternalServerError, dto.NewErrorOutput(err))
+		return
+	}
+
+	writeOutput(w, statusFor(violations, http.StatusOK), dto.NewAuthorizationOutput(authorization, violations))
+}
+
+func (h Handler) createAccount(w http.ResponseWriter, r *http.Request) {
+	var operation dto.AccountOperation
+
+	if err := decodeBody(r, &operation); err != nil {
+		writeOutput(w, http.StatusBadRequest, dto.Output{Violations: []string{}, Error: err.Error()})
+		return
+	}
+
+	account, violations, err := h.accountService.InitAccount(operation.ActiveCard, operation.AvailableLimit)
+
+	if err != nil {
+		writeOutput(w, http.StatusInternalServerError, dto.NewErrorOutput(err))
+		return
+	}
+
+	writeOutput(w, statusFor(violations, http.StatusCreated), dto.NewOutput(account, violations))
+}
+
+func (h Handler) getAccount(w http.ResponseWriter) {
+	account, violations, err := h.accountService.GetAccount()
+
+	if err != nil {
+		writeOutput(w, http.StatusInternalServerError, dto.NewErrorOutput(err))
+		return
+	}
+
+	writeOutput(w, statusFor(violations, http.StatusOK), dto.NewOutput(account, violations))
+}
+
+// statusFor map the violations to a status code, success is used when there are none
+func statusFor(violations []string, success int) int {
+	if len(violations) == 0 {
+		return success
+	}
+
+	switch violations[0] {
+	case domain.AccountNotInitializedViolation, domain.AuthorizationNotFoundViolation:
+		return http.StatusNotFound
+	case domain.AccountAlreadyInitializedViolation:
+		return http.StatusConflict
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+func decodeBody(r *http.Request, target interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return nil
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	for _, method := range allowed {
+		w.Header().Add("Allow", method)
+	}
+
+	writeOutput(w, http.StatusMethodNotAllowed, dto.Output{Violations: []string{}, Error: "method not allowed"})
+}
+
+func writeOutput(w http.ResponseWriter, status int, output dto.Output) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(output)
+}
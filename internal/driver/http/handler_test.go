@@ -0,0 +1,121 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+type request struct {
+	method string
+	path   string
+	body   string
+}
+
+type response struct {
+	status int
+	body   string
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		name     string
+		requests []request
+		expected []response
+	}{
+		{
+			name: "criando uma conta com sucesso",
+			requests: []request{
+				{method: http.MethodPost, path: "/accounts", body: `{"active-card":true,"available-limit":100}`},
+				{method: http.MethodGet, path: "/accounts"},
+			},
+			expected: []response{
+				{status: http.StatusCreated, body: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n"},
+				{status: http.StatusOK, body: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n"},
+			},
+		},
+		{
+			name: "criando uma conta que viola a lógica account-already-initialized",
+			requests: []request{
+				{method: http.MethodPost, path: "/accounts", body: `{"active-card":true,"available-limit":100}`},
+				{method: http.MethodPost, path: "/accounts", body: `{"active-card":false,"available-limit":350}`},
+			},
+			expected: []response{
+				{status: http.StatusCreated, body: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n"},
+				{status: http.StatusConflict, body: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[\"account-already-initialized\"]}\n"},
+			},
+		},
+		{
+			name: "consultando e autorizando sem conta inicializada",
+			requests: []request{
+				{method: http.MethodGet, path: "/accounts"},
+				{method: http.MethodPost, path: "/transactions", body: `{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00.000Z"}`},
+			},
+			expected: []response{
+				{status: http.StatusNotFound, body: "{\"account\":{},\"violations\":[\"account-not-initialized\"]}\n"},
+				{status: http.StatusNotFound, body: "{\"account\":{},\"violations\":[\"account-not-initialized\"],\"authorization-id\":\"00000000000000000001\"}\n"},
+			},
+		},
+		{
+			name: "processando transações com e sem violações",
+			requests: []request{
+				{method: http.MethodPost, path: "/accounts", body: `{"active-card":true,"available-limit":100}`},
+				{method: http.MethodPost, path: "/transactions", body: `{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00.000Z"}`},
+				{method: http.MethodPost, path: "/transactions", body: `{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:01.000Z"}`},
+				{method: http.MethodPost, path: "/transactions", body: `{"merchant":"Vivara","amount":1250,"time":"2019-02-13T11:00:02.000Z"}`},
+				{method: http.MethodGet, path: "/authorizations/00000000000000000002"},
+				{method: http.MethodGet, path: "/authorizations/00000000000000000009"},
+			},
+			expected: []response{
+				{status: http.StatusCreated, body: "{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n"},
+				{status: http.StatusOK, body: "{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n"},
+				{status: http.StatusUnprocessableEntity, body: "{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[\"doubled-transaction\"],\"authorization-id\":\"00000000000000000002\"}\n"},
+				{status: http.StatusUnprocessableEntity, body: "{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[\"insufficient-limit\"],\"authorization-id\":\"00000000000000000003\"}\n"},
+				{status: http.StatusOK, body: "{\"account\":{},\"authorization\":{\"id\":\"00000000000000000002\",\"merchant\":\"Burger King\",\"amount\":20,\"time\":\"2019-02-13T11:00:01Z\",\"available-limit\":80,\"violations\":[\"doubled-transaction\"]},\"violations\":[]}\n"},
+				{status: http.StatusNotFound, body: "{\"account\":{},\"violations\":[\"authorization-not-found\"]}\n"},
+			},
+		},
+		{
+			name: "enviando requisições inválidas",
+			requests: []request{
+				{method: http.MethodPost, path: "/accounts", body: `{"active-card":tru`},
+				{method: http.MethodDelete, path: "/transactions"},
+			},
+			expected: []response{
+				{status: http.StatusBadRequest, body: "{\"account\":{},\"violations\":[],\"error\":\"invalid request body: unexpected EOF\"}\n"},
+				{status: http.StatusMethodNotAllowed, body: "{\"account\":{},\"violations\":[],\"error\":\"method not allowed\"}\n"},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+			authorizationRepo := repository.NewAuthorizationRepository()
+			locker := lock.NewInMemoryLocker()
+
+			as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+			ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+			handler := NewHandler(as, ts)
+
+			for i, req := range tt.requests {
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, httptest.NewRequest(req.method, req.path, strings.NewReader(req.body)))
+
+				assert.Equal(t, tt.expected[i].status, recorder.Code)
+				assert.Equal(t, tt.expected[i].body, recorder.Body.String())
+				assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package iso8583
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/core/service"
+)
+
+const (
+	mtiAuthorizationRequest = "0100"
+	mtiFinancialRequest     = "0200"
+	transmissionLayout      = "0102150405"
+	merchantNameLength      = 22
+	maxFrameLength          = 1<<16 - 1
+
+	approvedResponseCode           = "00"
+	doNotHonorResponseCode         = "05"
+	invalidTransactionResponseCode = "12"
+	formatErrorResponseCode        = "30"
+	exceedsFrequencyResponseCode   = "65"
+	systemErrorResponseCode        = "96"
+)
+
+// responseCodes maps each violation to the ISO 8583 response code sent back to the network
+var responseCodes = map[string]string{
+	domain.AccountNotInitializedViolation: "14",
+	domain.CardNotActiveViolation:         "62",
+	domain.InsufficientLimitViolation:     "51",
+	domain.DoubledTransactionViolation:    "94",
+	domain.SystemErrorViolation:           systemErrorResponseCode,
+	domain.InvalidAmountViolation:         "13",
+	domain.InvalidMerchantViolation:       formatErrorResponseCode,
+	domain.InvalidTimeViolation:           formatErrorResponseCode,
+	domain.TransactionOutOfOrderViolation: invalidTransactionResponseCode,
+}
+
+// ruleResponseCodes maps each rule type to the response code sent when one of its rules fails
+var ruleResponseCodes = map[string]string{
+	domain.UsageLimitRule: exceedsFrequencyResponseCode,
+}
+
+// echoedFields are copied from the request into the response
+var echoedFields = []int{2, 3, 4, 7, 11, 12, 13, 18, 37, 41, 42, 49}
+
+// Handler authorizes 0100 and 0200 requests and answers with 0110 and 0210 responses
+type Handler struct {
+	transactionService service.Transaction
+	responseCodes      map[string]string
+	clock              ports.Clock
+}
+
+// NewHandler create a new Handler instance answering the violations of the default rules,
+// transmission years are inferred from the clock
+func NewHandler(ts service.Transaction, c ports.Clock) Handler {
+	return NewHandlerWithRules(ts, c, domain.DefaultRules())
+}
+
+// NewHandlerWithRules create a new Handler instance answering the violation of every rule with
+// the response code of its type
+func NewHandlerWithRules(ts service.Transaction, c ports.Clock, rules []domain.Rule) Handler {
+	codes := make(map[string]string, len(responseCodes)+len(rules))
+
+	for violation, code := range responseCodes {
+		codes[violation] = code
+	}
+
+	for _, rule := range rules {
+		code, exists := ruleResponseCodes[rule.Type]
+		if _, builtIn := responseCodes[rule.RuleViolation]; exists && !builtIn {
+			codes[rule.RuleViolation] = code
+		}
+	}
+
+	return Handler{transactionService: ts, responseCodes: codes, clock: c}
+}
+
+// Handle reads messages prefixed by their length as a 2 bytes big-endian integer
+// and writes every response with the same framing
+func (h Handler) Handle(r io.Reader, w io.Writer) error {
+	header := make([]byte, 2)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return fmt.Errorf("reading message length: %w", err)
+		}
+
+		frame := make([]byte, binary.BigEndian.Uint16(header))
+
+		if _, err := io.ReadFull(r, frame); err != nil {
+			return fmt.Errorf("reading message: %w", err)
+		}
+
+		response, err := h.HandleMessage(frame)
+		if err != nil {
+			return err
+		}
+
+		if response == nil {
+			continue
+		}
+
+		if len(response) > maxFrameLength {
+			return fmt.Errorf("response of %d bytes does not fit the frame", len(response))
+		}
+
+		binary.BigEndian.PutUint16(header, uint16(len(response)))
+
+		if _, err := w.Write(append(header, response...)); err != nil {
+			return err
+		}
+	}
+}
+
+// HandleMessage authorizes a single packed request and returns the packed response,
+// messages too broken to be answered produce no response
+func (h Handler) HandleMessage(data []byte) ([]byte, error) {
+	request, err := Unpack(data)
+
+	if err != nil && (len(request.MTI) != 4 || !isNumeric(request.MTI)) {
+		return nil, nil
+	}
+
+	if !isRequest(request.MTI) {
+		return nil, nil
+	}
+
+	var response Message
+
+	switch {
+	case err != nil:
+		response = buildResponse(request, formatErrorResponseCode)
+	case request.MTI != mtiAuthorizationRequest && request.MTI != mtiFinancialRequest:
+		response = buildResponse(request, invalidTransactionResponseCode)
+	default:
+		response = h.authorize(request)
+	}
+
+	return response.Pack()
+}
+
+func (h Handler) authorize(request Message) Message {
+	transaction, err := h.buildTransaction(request)
+	if err != nil {
+		return buildResponse(request, formatErrorResponseCode)
+	}
+
+	decision, err := h.transactionService.Authorize(transaction)
+	if err != nil {
+		return buildResponse(request, systemErrorResponseCode)
+	}
+
+	code := h.responseCode(decision.Violations)
+	response := buildResponse(request, code)
+
+	if code == approvedResponseCode {
+		response.Fields[38] = authorizationCode(request)
+	}
+
+	return response
+}
+
+func (h Handler) buildTransaction(request Message) (domain.Transaction, error) {
+	for _, field := range []int{4, 7, 43} {
+		if _, exists := request.Fields[field]; !exists {
+			return domain.Transaction{}, fmt.Errorf("%w: missing field %d", ErrInvalidMessage, field)
+		}
+	}
+
+	amount, err := strconv.ParseInt(request.Fields[4], 10, 64)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("%w: invalid amount", ErrInvalidMessage)
+	}
+
+	transmissionTime, err := h.transmissionTime(request.Fields[7])
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	return domain.Transaction{
+		Merchant: merchantName(request.Fields[43]),
+		Amount:   amount,
+		Time:     transmissionTime,
+	}, nil
+}
+
+// transmissionTime parses MMDDhhmmss in UTC, the year is the one that puts the
+// instant closest to the current time
+func (h Handler) transmissionTime(value string) (time.Time, error) {
+	parsed, err := time.Parse(transmissionLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid transmission date and time", ErrInvalidMessage)
+	}
+
+	now := h.clock.Now().UTC()
+	candidate := parsed.AddDate(now.Year(), 0, 0)
+
+	if candidate.Sub(now) > 180*24*time.Hour {
+		candidate = candidate.AddDate(-1, 0, 0)
+	} else if now.Sub(candidate) > 180*24*time.Hour {
+		candidate = candidate.AddDate(1, 0, 0)
+	}
+
+	if candidate.Month() != parsed.Month() {
+		return time.Time{}, fmt.Errorf("%w: invalid transmission date and time", ErrInvalidMessage)
+	}
+
+	return candidate, nil
+}
+
+func buildResponse(request Message, code string) Message {
+	response := NewMessage(responseMTI(request.MTI))
+
+	for _, field := range echoedFields {
+		if value, exists := request.Fields[field]; exists {
+			response.Fields[field] = value
+		}
+	}
+
+	response.Fields[39] = code
+
+	return response
+}
+
+// isRequest tells requests apart from responses, whose message function digit is odd
+func isRequest(mti string) bool {
+	return (mti[2]-'0')%2 == 0
+}
+
+func responseMTI(mti string) string {
+	return mti[:2] + string(mti[2]+1) + mti[3:]
+}
+
+func (h Handler) responseCode(violations []string) string {
+	if len(violations) == 0 {
+		return approvedResponseCode
+	}
+
+	if code, exists := h.responseCodes[violations[0]]; exists {
+		return code
+	}
+
+	return doNotHonorResponseCode
+}
+
+// authorizationCode derives the approval code from the trace number
+func authorizationCode(request Message) string {
+	if stan, exists := request.Fields[11]; exists {
+		return stan
+	}
+
+	return "000000"
+}
+
+func merchantName(cardAcceptor string) string {
+	if len(cardAcceptor) > merchantNameLength {
+		cardAcceptor = cardAcceptor[:merchantNameLength]
+	}
+
+	return strings.TrimSpace(cardAcceptor)
+}
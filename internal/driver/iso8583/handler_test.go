@@ -0,0 +1,191 @@
+package iso8583
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func buildRequest(mti, stan, amount, merchant string) Message {
+	msg := NewMessage(mti)
+	msg.Fields[2] = "4111111111111111"
+	msg.Fields[3] = "000000"
+	msg.Fields[4] = amount
+	msg.Fields[7] = "0213110000"
+	msg.Fields[11] = stan
+	msg.Fields[18] = "5814"
+	msg.Fields[43] = merchant
+
+	return msg
+}
+
+func frame(t *testing.T, messages ...Message) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	for _, msg := range messages {
+		packed, err := msg.Pack()
+		assert.NoError(t, err)
+
+		header := make([]byte, 2)
+		binary.BigEndian.PutUint16(header, uint16(len(packed)))
+		buf.Write(header)
+		buf.Write(packed)
+	}
+
+	return &buf
+}
+
+func readResponses(t *testing.T, r io.Reader) []Message {
+	var responses []Message
+	header := make([]byte, 2)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return responses
+		}
+
+		data := make([]byte, binary.BigEndian.Uint16(header))
+		_, _ = io.ReadFull(r, data)
+
+		msg, err := Unpack(data)
+		assert.NoError(t, err)
+
+		responses = append(responses, msg)
+	}
+}
+
+func TestHandler_Handle(t *testing.T) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	authorizationRepo := repository.NewAuthorizationRepository()
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+	_, _, _ = as.InitAccount(true, 100)
+
+	handler := NewHandler(ts, clock.NewFakeClock(time.Date(2019, 2, 13, 12, 0, 0, 0, time.UTC)))
+
+	missingMerchant := buildRequest("0100", "000005", "000000000010", "")
+	delete(missingMerchant.Fields, 43)
+
+	input := frame(t,
+		buildRequest("0100", "000001", "000000000020", "Burger King"),
+		buildRequest("0100", "000002", "000000000020", "Burger King"),
+		buildRequest("0200", "000003", "000000001250", "Vivara"),
+		buildRequest("0400", "000004", "000000000020", "Burger King"),
+		missingMerchant,
+		buildRequest("0100", "000007", "000000000000", "Burger King"),
+		buildRequest("0100", "000008", "000000000010", "   "),
+		buildRequest("0110", "000006", "000000000020", "Burger King"),
+	)
+
+	var output bytes.Buffer
+
+	err := handler.Handle(input, &output)
+
+	assert.NoError(t, err)
+
+	responses := readResponses(t, &output)
+
+	assert.Len(t, responses, 7)
+
+	expected := []struct {
+		mti  string
+		stan string
+		code string
+	}{
+		{mti: "0110", stan: "000001", code: "00"},
+		{mti: "0110", stan: "000002", code: "94"},
+		{mti: "0210", stan: "000003", code: "51"},
+		{mti: "0410", stan: "000004", code: "12"},
+		{mti: "0110", stan: "000005", code: "30"},
+		{mti: "0110", stan: "000007", code: "13"},
+		{mti: "0110", stan: "000008", code: "30"},
+	}
+
+	for i, e := range expected {
+		assert.Equal(t, e.mti, responses[i].MTI)
+		assert.Equal(t, e.stan, responses[i].Fields[11])
+		assert.Equal(t, e.code, responses[i].Fields[39])
+		assert.Equal(t, "5814", responses[i].Fields[18])
+	}
+
+	assert.Equal(t, "000001", responses[0].Fields[38])
+	assert.NotContains(t, responses[1].Fields, 38)
+}
+
+func TestHandler_TransmissionTime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		now      time.Time
+		value    string
+		expected time.Time
+	}{
+		{
+			name:     "transmissão no mesmo ano",
+			now:      time.Date(2019, 2, 13, 12, 0, 0, 0, time.UTC),
+			value:    "0213110000",
+			expected: time.Date(2019, 2, 13, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "transmissão na virada do ano",
+			now:      time.Date(2020, 1, 1, 0, 0, 5, 0, time.UTC),
+			value:    "1231235959",
+			expected: time.Date(2019, 12, 31, 23, 59, 59, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := Handler{clock: clock.NewFakeClock(tt.now)}
+
+			result, err := handler.transmissionTime(tt.value)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := Handler{clock: clock.NewSystemClock()}.transmissionTime("1332000000")
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestHandler_ResponseCode(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "max 2 transactions in 1 minute", Type: domain.UsageLimitRule, UsageLimit: 2, RuleViolation: "velocity-exceeded"},
+		{Name: "unknown type", Type: "amount-limit", RuleViolation: "amount-exceeded"},
+		{Name: "reusing a violation", Type: domain.UsageLimitRule, UsageLimit: 1, RuleViolation: domain.InsufficientLimitViolation},
+	}
+
+	testCases := []struct {
+		name       string
+		handler    Handler
+		violations []string
+		expected   string
+	}{
+		{name: "aprovada", handler: NewHandler(service.Transaction{}, clock.NewSystemClock()), violations: nil, expected: "00"},
+		{name: "regra padrão", handler: NewHandler(service.Transaction{}, clock.NewSystemClock()), violations: []string{"high-frequency-small-interval"}, expected: "65"},
+		{name: "fora de ordem", handler: NewHandler(service.Transaction{}, clock.NewSystemClock()), violations: []string{domain.TransactionOutOfOrderViolation}, expected: "12"},
+		{name: "regra configurada", handler: NewHandlerWithRules(service.Transaction{}, clock.NewSystemClock(), rules), violations: []string{"velocity-exceeded"}, expected: "65"},
+		{name: "regra padrão fora da configuração", handler: NewHandlerWithRules(service.Transaction{}, clock.NewSystemClock(), rules), violations: []string{"high-frequency-small-interval"}, expected: "05"},
+		{name: "tipo de regra sem código", handler: NewHandlerWithRules(service.Transaction{}, clock.NewSystemClock(), rules), violations: []string{"amount-exceeded"}, expected: "05"},
+		{name: "violação padrão usada por regra", handler: NewHandlerWithRules(service.Transaction{}, clock.NewSystemClock(), rules), violations: []string{domain.InsufficientLimitViolation}, expected: "51"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.handler.responseCode(tt.violations))
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package iso8583
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidMessage is wrapped by every parsing failure
+var ErrInvalidMessage = errors.New("invalid iso8583 message")
+
+type lengthType int
+
+const (
+	fixed lengthType = iota
+	llvar
+)
+
+type fieldSpec struct {
+	length     lengthType
+	maxLength  int
+	numeric    bool
+	padSpaces  bool
+	descriptor string
+}
+
+// specs lists the data elements this adapter understands, in the ASCII encoding
+var specs = map[int]fieldSpec{
+	2:  {length: llvar, maxLength: 19, numeric: true, descriptor: "primary account number"},
+	3:  {length: fixed, maxLength: 6, numeric: true, descriptor: "processing code"},
+	4:  {length: fixed, maxLength: 12, numeric: true, descriptor: "transaction amount"},
+	7:  {length: fixed, maxLength: 10, numeric: true, descriptor: "transmission date and time"},
+	11: {length: fixed, maxLength: 6, numeric: true, descriptor: "system trace audit number"},
+	12: {length: fixed, maxLength: 6, numeric: true, descriptor: "local transaction time"},
+	13: {length: fixed, maxLength: 4, numeric: true, descriptor: "local transaction date"},
+	18: {length: fixed, maxLength: 4, numeric: true, descriptor: "merchant category code"},
+	37: {length: fixed, maxLength: 12, padSpaces: true, descriptor: "retrieval reference number"},
+	38: {length: fixed, maxLength: 6, padSpaces: true, descriptor: "authorization identification response"},
+	39: {length: fixed, maxLength: 2, padSpaces: true, descriptor: "response code"},
+	41: {length: fixed, maxLength: 8, padSpaces: true, descriptor: "card acceptor terminal identification"},
+	42: {length: fixed, maxLength: 15, padSpaces: true, descriptor: "card acceptor identification code"},
+	43: {length: fixed, maxLength: 40, padSpaces: true, descriptor: "card acceptor name and location"},
+	49: {length: fixed, maxLength: 3, numeric: true, descriptor: "transaction currency code"},
+}
+
+// Message is an ISO 8583 message encoded in ASCII: the MTI, the bitmap as hexadecimal
+// characters (a secondary bitmap follows when bit 1 is set) and the data elements
+type Message struct {
+	MTI    string
+	Fields map[int]string
+}
+
+// NewMessage create a new Message instance
+func NewMessage(mti string) Message {
+	return Message{MTI: mti, Fields: make(map[int]string)}
+}
+
+// Unpack parse an ASCII encoded message
+func Unpack(data []byte) (Message, error) {
+	if len(data) < 4+16 {
+		return Message{}, fmt.Errorf("%w: message too short", ErrInvalidMessage)
+	}
+
+	msg := NewMessage(string(data[:4]))
+
+	if !isNumeric(msg.MTI) {
+		return msg, fmt.Errorf("%w: invalid MTI %q", ErrInvalidMessage, msg.MTI)
+	}
+
+	bitmap, err := hex.DecodeString(string(data[4:20]))
+	if err != nil {
+		return msg, fmt.Errorf("%w: invalid primary bitmap", ErrInvalidMessage)
+	}
+
+	pos := 20
+
+	if bitmap[0]&0x80 != 0 {
+		if len(data) < pos+16 {
+			return msg, fmt.Errorf("%w: missing secondary bitmap", ErrInvalidMessage)
+		}
+
+		secondary, err := hex.DecodeString(string(data[pos : pos+16]))
+		if err != nil {
+			return msg, fmt.Errorf("%w: invalid secondary bitmap", ErrInvalidMessage)
+		}
+
+		bitmap = append(bitmap, secondary...)
+		pos += 16
+	}
+
+	for field := 2; field <= len(bitmap)*8; field++ {
+		if !bitSet(bitmap, field) {
+			continue
+		}
+
+		value, next, err := unpackField(data, pos, field)
+		if err != nil {
+			return msg, err
+		}
+
+		msg.Fields[field] = value
+		pos = next
+	}
+
+	if pos != len(data) {
+		return msg, fmt.Errorf("%w: %d trailing bytes", ErrInvalidMessage, len(data)-pos)
+	}
+
+	return msg, nil
+}
+
+// Pack encode the message in ASCII
+func (m Message) Pack() ([]byte, error) {
+	if len(m.MTI) != 4 || !isNumeric(m.MTI) {
+		return nil, fmt.Errorf("%w: invalid MTI %q", ErrInvalidMessage, m.MTI)
+	}
+
+	fields := make([]int, 0, len(m.Fields))
+	for field := range m.Fields {
+		fields = append(fields, field)
+	}
+	sort.Ints(fields)
+
+	bitmap := make([]byte, 8)
+	if len(fields) > 0 && fields[len(fields)-1] > 64 {
+		bitmap = make([]byte, 16)
+		bitmap[0] |= 0x80
+	}
+
+	var body strings.Builder
+
+	for _, field := range fields {
+		encoded, err := packField(field, m.Fields[field])
+		if err != nil {
+			return nil, err
+		}
+
+		bitmap[(field-1)/8] |= 0x80 >> uint((field-1)%8)
+		body.WriteString(encoded)
+	}
+
+	return []byte(m.MTI + strings.ToUpper(hex.EncodeToString(bitmap)) + body.String()), nil
+}
+
+func unpackField(data []byte, pos int, field int) (string, int, error) {
+	spec, known := specs[field]
+	if !known {
+		return "", pos, fmt.Errorf("%w: unsupported field %d", ErrInvalidMessage, field)
+	}
+
+	length := spec.maxLength
+
+	if spec.length == llvar {
+		if len(data) < pos+2 {
+			return "", pos, fmt.Errorf("%w: field %d truncated", ErrInvalidMessage, field)
+		}
+
+		l, err := strconv.Atoi(string(data[pos : pos+2]))
+		if err != nil || l > spec.maxLength {
+			return "", pos, fmt.Errorf("%w: invalid length for field %d", ErrInvalidMessage, field)
+		}
+
+		length = l
+		pos += 2
+	}
+
+	if len(data) < pos+length {
+		return "", pos, fmt.Errorf("%w: field %d truncated", ErrInvalidMessage, field)
+	}
+
+	value := string(data[pos : pos+length])
+
+	if spec.numeric && !isNumeric(value) {
+		return "", pos, fmt.Errorf("%w: field %d (%s) must be numeric", ErrInvalidMessage, field, spec.descriptor)
+	}
+
+	return value, pos + length, nil
+}
+
+func packField(field int, value string) (string, error) {
+	spec, known := specs[field]
+	if !known {
+		return "", fmt.Errorf("%w: unsupported field %d", ErrInvalidMessage, field)
+	}
+
+	if len(value) > spec.maxLength {
+		return "", fmt.Errorf("%w: field %d (%s) longer than %d", ErrInvalidMessage, field, spec.descriptor, spec.maxLength)
+	}
+
+	if spec.numeric && !isNumeric(value) {
+		return "", fmt.Errorf("%w: field %d (%s) must be numeric", ErrInvalidMessage, field, spec.descriptor)
+	}
+
+	if spec.length == llvar {
+		return fmt.Sprintf("%02d%s", len(value), value), nil
+	}
+
+	if spec.padSpaces {
+		return value + strings.Repeat(" ", spec.maxLength-len(value)), nil
+	}
+
+	return strings.Repeat("0", spec.maxLength-len(value)) + value, nil
+}
+
+func bitSet(bitmap []byte, field int) bool {
+	return bitmap[(field-1)/8]&(0x80>>uint((field-1)%8)) != 0
+}
+
+func isNumeric(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
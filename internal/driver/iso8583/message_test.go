@@ -0,0 +1,56 @@
+package iso8583
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage_Pack_Unpack(t *testing.T) {
+	msg := NewMessage("0100")
+	msg.Fields[2] = "4111111111111111"
+	msg.Fields[3] = "000000"
+	msg.Fields[4] = "000000002000"
+	msg.Fields[7] = "0213110000"
+	msg.Fields[11] = "000123"
+	msg.Fields[18] = "5814"
+	msg.Fields[43] = "Burger King"
+
+	packed, err := msg.Pack()
+
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"0100722040000020000016411111111111111100000000000000200002131100000001235814Burger King                             ",
+		string(packed))
+
+	unpacked, err := Unpack(packed)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0100", unpacked.MTI)
+	assert.Equal(t, "Burger King                             ", unpacked.Fields[43])
+	assert.Equal(t, "4111111111111111", unpacked.Fields[2])
+	assert.Len(t, unpacked.Fields, 7)
+}
+
+func TestUnpack_Invalid_Messages(t *testing.T) {
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{name: "mensagem curta demais", data: "0100"},
+		{name: "MTI não numérico", data: "01A07000000000000000"},
+		{name: "bitmap inválido", data: "0100ZZ00000000000000"},
+		{name: "campo não suportado", data: "01000000000000000001"},
+		{name: "campo truncado", data: "0100100000000000000000012"},
+		{name: "campo numérico com letras", data: "0100300000000000000000000A"},
+		{name: "bytes sobrando", data: "01002000000000000000000000XX"},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Unpack([]byte(tt.data))
+
+			assert.ErrorIs(t, err, ErrInvalidMessage)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/dto"
+)
+
+const version = "2.0"
+
+// Standard JSON-RPC 2.0 error codes
+const (
+	ParseErrorCode     = -32700
+	InvalidRequestCode = -32600
+	MethodNotFoundCode = -32601
+	InvalidParamsCode  = -32602
+	InternalErrorCode  = -32603
+)
+
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type method func(params json.RawMessage) (interface{}, *Error)
+
+// Handler serves JSON-RPC 2.0 calls, one request or batch per line
+type Handler struct {
+	accountService     service.Account
+	transactionService service.Transaction
+	methods            map[string]method
+}
+
+// NewHandler create a new Handler instance with its methods registered
+func NewHandler(as service.Account, ts service.Transaction) Handler {
+	h := Handler{accountService: as, transactionService: ts}
+
+	h.methods = map[string]method{
+		"account.create":        h.createAccount,
+		"account.get":           h.getAccount,
+		"transaction.authorize": h.authorize,
+		"authorization.get":     h.getAuthorization,
+	}
+
+	return h
+}
+
+// Handle reads requests until the input ends and writes one response line for each
+// line that is not made only of notifications
+func (h Handler) Handle(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := h.HandleMessage(line); resp != nil {
+				if _, werr := fmt.Fprintln(w, string(resp)); werr != nil {
+					return werr
+				}
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+	}
+}
+
+// HandleMessage answers a single request or batch, returning nil when nothing must be sent back
+func (h Handler) HandleMessage(message []byte) []byte {
+	message = bytes.TrimSpace(message)
+
+	if !json.Valid(message) {
+		return marshal(errorResponse(nil, ParseErrorCode, "Parse error"))
+	}
+
+	if message[0] != '[' {
+		resp := h.call(message)
+		if resp == nil {
+			return nil
+		}
+
+		return marshal(resp)
+	}
+
+	var batch []json.RawMessage
+	_ = json.Unmarshal(message, &batch)
+
+	if len(batch) == 0 {
+		return marshal(errorResponse(nil, InvalidRequestCode, "Invalid Request"))
+	}
+
+	responses := make([]*Response, 0, len(batch))
+
+	for _, raw := range batch {
+		if resp := h.call(raw); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+
+	if len(responses) == 0 {
+		return nil
+	}
+
+	return marshal(responses)
+}
+
+func (h Handler) call(raw json.RawMessage) *Response {
+	var req Request
+
+	if err := json.Unmarshal(raw, &req); err != nil || !validID(req.ID) {
+		return errorResponse(nil, InvalidRequestCode, "Invalid Request")
+	}
+
+	if req.JSONRPC != version || req.Method == "" {
+		return errorResponse(req.ID, InvalidRequestCode, "Invalid Request")
+	}
+
+	m, exists := h.methods[req.Method]
+
+	var (
+		result interface{}
+		rpcErr *Error
+	)
+
+	if exists {
+		result, rpcErr = m(req.Params)
+	} else {
+		rpcErr = &Error{Code: MethodNotFoundCode, Message: "Method not found"}
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+
+	if rpcErr != nil {
+		return &Response{JSONRPC: version, Error: rpcErr, ID: req.ID}
+	}
+
+	return &Response{JSONRPC: version, Result: result, ID: req.ID}
+}
+
+func (h Handler) createAccount(params json.RawMessage) (interface{}, *Error) {
+	var operation dto.AccountOperation
+
+	if rpcErr := decodeParams(params, &operation); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	account, violations, err := h.accountService.InitAccount(operation.ActiveCard, operation.AvailableLimit)
+
+	return buildResult(account, violations, err)
+}
+
+func (h Handler) getAccount(params json.RawMessage) (interface{}, *Error) {
+	var empty struct{}
+
+	if rpcErr := decodeParams(params, &empty); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	account, violations, err := h.accountService.GetAccount()
+
+	return buildResult(account, violations, err)
+}
+
+func (h Handler) authorize(params json.RawMessage) (interface{}, *Error) {
+	var operation dto.TransactionOperation
+
+	if rpcErr := decodeParams(params, &operation); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	decision, err := h.transactionService.Authorize(domain.Transaction{
+		Merchant: operation.Merchant,
+		Amount:   operation.Amount,
+		Time:     operation.Time,
+	})
+
+	if err != nil {
+		return buildResult(nil, nil, err)
+	}
+
+	return dto.NewDecisionOutput(decision, false), nil
+}
+
+func (h Handler) getAuthorization(params json.RawMessage) (interface{}, *Error) {
+	var operation dto.GetAuthorizationOperation
+
+	if rpcErr := decodeParams(params, &operation); rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	authorization, violations, err := h.transactionService.GetAuthorization(operation.ID)
+	if err != nil {
+		return buildResult(nil, nil, err)
+	}
+
+	return dto.NewAuthorizationOutput(authorization, violations), nil
+}
+
+func buildResult(account *domain.Account, violations []string, err error) (interface{}, *Error) {
+	if err != nil {
+		return nil, &Error{Code: InternalErrorCode, Message: "Internal error", Data: dto.NewErrorOutput(err)}
+	}
+
+	return dto.NewOutput(account, violations), nil
+}
+
+// decodeParams accepts params by name only, absent params decode as an empty object
+func decodeParams(params json.RawMessage, target interface{}) *Error {
+	if len(params) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+
+	if params[0] != '{' {
+		return &Error{Code: InvalidParamsCode, Message: "Invalid params", Data: "params must be an object"}
+	}
+
+	if err := decoder.Decode(target); err != nil {
+		return &Error{Code: InvalidParamsCode, Message: "Invalid params", Data: err.Error()}
+	}
+
+	return nil
+}
+
+// validID accepts the id types allowed by the specification: string, number, null or absent
+func validID(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+
+	var value interface{}
+	_ = json.Unmarshal(id, &value)
+
+	switch value.(type) {
+	case nil, string, float64:
+		return true
+	}
+
+	return false
+}
+
+func errorResponse(id json.RawMessage, code int, message string) *Response {
+	return &Response{
+		JSONRPC: version,
+		Error:   &Error{Code: code, Message: message},
+		ID:      id,
+	}
+}
+
+func marshal(v interface{}) []byte {
+	jm, _ := json.Marshal(v)
+	return jm
+}
@@ -0,0 +1,95 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_Handle(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "criando e consultando uma conta",
+			input: `{"jsonrpc":"2.0","method":"account.create","params":{"active-card":true,"available-limit":100},"id":1}
+{"jsonrpc":"2.0","method":"account.get","id":"consulta"}
+`,
+			expected: `{"jsonrpc":"2.0","result":{"account":{"active-card":true,"available-limit":100},"violations":[]},"id":1}
+{"jsonrpc":"2.0","result":{"account":{"active-card":true,"available-limit":100},"violations":[]},"id":"consulta"}
+`,
+		},
+		{
+			name: "autorizando transações com violações",
+			input: `{"jsonrpc":"2.0","method":"transaction.authorize","params":{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00.000Z"},"id":1}
+{"jsonrpc":"2.0","method":"account.create","params":{"active-card":true,"available-limit":100},"id":2}
+{"jsonrpc":"2.0","method":"transaction.authorize","params":{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00.000Z"},"id":3}
+{"jsonrpc":"2.0","method":"transaction.authorize","params":{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:01.000Z"},"id":4}
+{"jsonrpc":"2.0","method":"authorization.get","params":{"id":"00000000000000000002"},"id":5}
+`,
+			expected: `{"jsonrpc":"2.0","result":{"account":{},"violations":["account-not-initialized"],"authorization-id":"00000000000000000001"},"id":1}
+{"jsonrpc":"2.0","result":{"account":{"active-card":true,"available-limit":100},"violations":[]},"id":2}
+{"jsonrpc":"2.0","result":{"account":{"active-card":true,"available-limit":80},"violations":[],"authorization-id":"00000000000000000002"},"id":3}
+{"jsonrpc":"2.0","result":{"account":{"active-card":true,"available-limit":80},"violations":["doubled-transaction"],"authorization-id":"00000000000000000003"},"id":4}
+{"jsonrpc":"2.0","result":{"account":{},"authorization":{"id":"00000000000000000002","merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00Z","available-limit":100,"violations":[]},"violations":[]},"id":5}
+`,
+		},
+		{
+			name: "enviando um lote com notificações",
+			input: `[{"jsonrpc":"2.0","method":"account.create","params":{"active-card":true,"available-limit":100}},{"jsonrpc":"2.0","method":"account.get","id":1},{"jsonrpc":"2.0","method":"account.delete","id":2},1]
+[{"jsonrpc":"2.0","method":"account.get"}]
+`,
+			expected: `[{"jsonrpc":"2.0","result":{"account":{"active-card":true,"available-limit":100},"violations":[]},"id":1},{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found"},"id":2},{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}]
+`,
+		},
+		{
+			name: "enviando requisições inválidas",
+			input: `{"jsonrpc":"2.0","method":"account.create","params":{"active-card":tru
+[]
+{"jsonrpc":"1.0","method":"account.get","id":1}
+{"jsonrpc":"2.0","method":"account.get","id":{"a":1}}
+{"jsonrpc":"2.0","method":1,"id":1}
+{"jsonrpc":"2.0","method":"account.create","params":[true,100],"id":1}
+{"jsonrpc":"2.0","method":"account.create","params":{"active-card":"sim"},"id":2}
+{"jsonrpc":"2.0","method":"account.get","id":null}
+`,
+			expected: `{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}
+{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}
+{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":1}
+{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}
+{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}
+{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"params must be an object"},"id":1}
+{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"json: cannot unmarshal string into Go struct field AccountOperation.active-card of type bool"},"id":2}
+{"jsonrpc":"2.0","result":{"account":{},"violations":["account-not-initialized"]},"id":null}
+`,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+
+			accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+			authorizationRepo := repository.NewAuthorizationRepository()
+			locker := lock.NewInMemoryLocker()
+
+			as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+			ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+
+			err := NewHandler(as, ts).Handle(strings.NewReader(tt.input), &stdout)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, stdout.String())
+		})
+	}
+}
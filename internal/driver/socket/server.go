@@ -0,0 +1,200 @@
+package socket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/authorizer/internal/driven/logging"
+)
+
+// Delays between accept retries, doubled on every consecutive failure up to the maximum
+const (
+	minAcceptDelay = 5 * time.Millisecond
+	maxAcceptDelay = time.Second
+)
+
+// Handler speaks a protocol over a single connection until the input ends
+type Handler interface {
+	Handle(r io.Reader, w io.Writer) error
+}
+
+// Server accepts connections on a TCP or Unix socket and runs the Handler on each
+// of them, all connections share the same services
+type Server struct {
+	handler Handler
+	logger  *logging.Logger
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closing   bool
+	wg        sync.WaitGroup
+}
+
+// NewServer create a new Server instance reporting failed accepts and connections to the logger
+func NewServer(h Handler, logger *logging.Logger) *Server {
+	return &Server{
+		handler:   h,
+		logger:    logger,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+}
+
+// ErrServerClosed is returned by Serve after Shutdown
+var ErrServerClosed = errors.New("socket: server closed")
+
+// Serve accepts connections on l until Shutdown is called. Accept failures other than the
+// listener being closed are retried, backing off while they keep failing
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l) {
+		return ErrServerClosed
+	}
+
+	var delay time.Duration
+
+	for {
+		conn, err := l.Accept()
+
+		if err != nil {
+			if s.isClosing() {
+				return ErrServerClosed
+			}
+
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+
+			if delay = 2 * delay; delay == 0 {
+				delay = minAcceptDelay
+			} else if delay > maxAcceptDelay {
+				delay = maxAcceptDelay
+			}
+
+			s.logger.Warn("accept failed", logging.Field{Key: "error", Value: err}, logging.Field{Key: "retry-in", Value: delay.String()})
+			time.Sleep(delay)
+
+			continue
+		}
+
+		delay = 0
+
+		if !s.trackConn(conn) {
+			_ = conn.Close()
+			continue
+		}
+
+		go s.serveConn(conn)
+	}
+}
+
+// Shutdown stops accepting connections and waits for the open ones to finish the
+// operation in flight, connections still open when ctx is done are closed
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+
+	for l := range s.listeners {
+		_ = l.Close()
+	}
+
+	for conn := range s.conns {
+		_ = conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		for conn := range s.conns {
+			_ = conn.Close()
+		}
+		s.mu.Unlock()
+
+		return ctx.Err()
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer s.untrackConn(conn)
+
+	err := s.handler.Handle(drainReader{conn: conn, server: s}, conn)
+
+	if err != nil {
+		s.logger.Warn("connection failed", logging.Field{Key: "remote", Value: conn.RemoteAddr().String()}, logging.Field{Key: "error", Value: err})
+	}
+}
+
+func (s *Server) trackListener(l net.Listener) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return false
+	}
+
+	s.listeners[l] = struct{}{}
+
+	return true
+}
+
+func (s *Server) trackConn(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing {
+		return false
+	}
+
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+
+	return true
+}
+
+func (s *Server) untrackConn(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+
+	_ = conn.Close()
+}
+
+func (s *Server) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closing
+}
+
+// drainReader turns the read deadline set by Shutdown into the end of the input,
+// so the handler stops after the operation in flight
+type drainReader struct {
+	conn   net.Conn
+	server *Server
+}
+
+func (r drainReader) Read(p []byte) (int, error) {
+	n, err := r.conn.Read(p)
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() && r.server.isClosing() {
+		return n, io.EOF
+	}
+
+	return n, err
+}
@@ -0,0 +1,164 @@
+package socket
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/logging"
+	"github.com/authorizer/internal/driven/repository"
+	"github.com/authorizer/internal/driver/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func startServer(t *testing.T, network, address string) (*Server, net.Listener, chan error) {
+	accountRepo := repository.NewInMemoryAccountRepository(clock.NewSystemClock())
+	authorizationRepo := repository.NewAuthorizationRepository()
+	locker := lock.NewInMemoryLocker()
+
+	as := service.NewAccount(accountRepo, locker, clock.NewSystemClock())
+	ts := service.NewTransaction(accountRepo, authorizationRepo, locker, identifier.NewSequentialGenerator(), accountRepo.Outbox(), domain.RetentionPolicy{})
+	is := service.NewIdempotency(repository.NewIdempotencyRepository(), clock.NewSystemClock(), time.Hour)
+
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := NewServer(cli.NewHandler(as, ts, is), logging.NewLogger(&bytes.Buffer{}, logging.InfoLevel, clock.NewSystemClock()))
+	served := make(chan error, 1)
+
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	return server, listener, served
+}
+
+func send(t *testing.T, conn net.Conn, reader *bufio.Reader, line string) string {
+	_, err := fmt.Fprintln(conn, line)
+	assert.NoError(t, err)
+
+	resp, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+
+	return resp
+}
+
+func TestServer_Shares_State_Across_Connections(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			address := "127.0.0.1:0"
+			if network == "unix" {
+				address = filepath.Join(t.TempDir(), "authorizer.sock")
+			}
+
+			server, listener, served := startServer(t, network, address)
+
+			first, err := net.Dial(network, listener.Addr().String())
+			assert.NoError(t, err)
+			second, err := net.Dial(network, listener.Addr().String())
+			assert.NoError(t, err)
+
+			firstReader := bufio.NewReader(first)
+			secondReader := bufio.NewReader(second)
+
+			assert.Equal(t,
+				"{\"account\":{\"active-card\":true,\"available-limit\":100},\"violations\":[]}\n",
+				send(t, first, firstReader, `{"account":{"active-card":true,"available-limit":100}}`))
+			assert.Equal(t,
+				"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n",
+				send(t, second, secondReader, `{"transaction":{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00.000Z"}}`))
+			assert.Equal(t,
+				"{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[\"doubled-transaction\"],\"authorization-id\":\"00000000000000000002\"}\n",
+				send(t, first, firstReader, `{"transaction":{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:01.000Z"}}`))
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			assert.NoError(t, server.Shutdown(ctx))
+			assert.ErrorIs(t, <-served, ErrServerClosed)
+
+			_, err = firstReader.ReadString('\n')
+			assert.Error(t, err)
+			_, err = secondReader.ReadString('\n')
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestServer_Shutdown_Stops_Accepting_Connections(t *testing.T) {
+	server, listener, served := startServer(t, "tcp", "127.0.0.1:0")
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	send(t, conn, reader, `{"account":{"active-card":true,"available-limit":100}}`)
+
+	_, err = fmt.Fprint(conn, `{"transaction":{"merchant":"Burger King","amount":20,"time":"2019-02-13T11:00:00.000Z"}}`+"\n")
+	assert.NoError(t, err)
+
+	resp, err := reader.ReadString('\n')
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, server.Shutdown(ctx))
+	assert.ErrorIs(t, <-served, ErrServerClosed)
+	assert.Equal(t, "{\"account\":{\"active-card\":true,\"available-limit\":80},\"violations\":[],\"authorization-id\":\"00000000000000000001\"}\n", resp)
+
+	_, err = net.Dial("tcp", listener.Addr().String())
+	assert.Error(t, err)
+}
+
+// failingListener fails every Accept with the errors given, in order
+type failingListener struct {
+	net.Listener
+	errs []error
+}
+
+func (l *failingListener) Accept() (net.Conn, error) {
+	err := l.errs[0]
+	l.errs = l.errs[1:]
+
+	return nil, err
+}
+
+func TestServer_Serve_Accept_Errors(t *testing.T) {
+	acceptErr := errors.New("accept4: too many open files")
+
+	testCases := []struct {
+		name            string
+		errs            []error
+		expectedRetries int
+	}{
+		{name: "listener fechado por fora do servidor", errs: []error{net.ErrClosed}, expectedRetries: 0},
+		{name: "falhas repetidas antes do listener fechar", errs: []error{acceptErr, acceptErr, net.ErrClosed}, expectedRetries: 2},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+
+			server := NewServer(nil, logging.NewLogger(&logs, logging.InfoLevel, clock.NewSystemClock()))
+
+			err := server.Serve(&failingListener{errs: tt.errs})
+
+			assert.ErrorIs(t, err, net.ErrClosed)
+			assert.Equal(t, tt.expectedRetries, bytes.Count(logs.Bytes(), []byte(`"msg":"accept failed"`)))
+		})
+	}
+}
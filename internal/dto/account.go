@@ -27,15 +27,8 @@ type Ledger struct {
 	AvailableLimit int64 `json:"available_limit"`
 }
 
-type TransactionAuthorization struct {
-	Merchant       string    `json:"merchant"`
-	Amount         int64     `json:"amount"`
-	AvailableLimit int64     `json:"available_limit"`
-	Time           time.Time `json:"time"`
-}
-
 type Account struct {
-	Ledger          Ledger                     `json:"ledger"`
-	SpendingControl SpendingControl            `json:"spending_control"`
-	Transactions    []TransactionAuthorization `json:"transactions"`
+	SchemaVersion   int             `json:"schema_version"`
+	Ledger          Ledger          `json:"ledger"`
+	SpendingControl SpendingControl `json:"spending_control"`
 }
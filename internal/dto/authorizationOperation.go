@@ -0,0 +1,5 @@
+package dto
+
+type GetAuthorizationOperation struct {
+	ID string `json:"id"`
+}
@@ -0,0 +1,163 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// EventRule is a spending control rule as configured when the account was created
+type EventRule struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	UsageLimit    int64  `json:"usage-limit"`
+	Duration      string `json:"duration,omitempty"`
+	RuleViolation string `json:"rule-violation"`
+}
+
+// Event is the JSON form of every domain event, fields that do not apply to the type are omitted
+type Event struct {
+	Type            string      `json:"type"`
+	AccountID       int64       `json:"account-id"`
+	Time            time.Time   `json:"time"`
+	AuthorizationID string      `json:"authorization-id,omitempty"`
+	Merchant        string      `json:"merchant,omitempty"`
+	Amount          int64       `json:"amount,omitempty"`
+	ActiveCard      *bool       `json:"active-card,omitempty"`
+	MaxLimit        *int64      `json:"max-limit,omitempty"`
+	AvailableLimit  *int64      `json:"available-limit,omitempty"`
+	Violations      []string    `json:"violations,omitempty"`
+	Rule            string      `json:"rule,omitempty"`
+	Violation       string      `json:"violation,omitempty"`
+	Rules           []EventRule `json:"rules,omitempty"`
+}
+
+// NewEvent build the JSON form of a domain event
+func NewEvent(event domain.Event) Event {
+	output := Event{Type: event.EventType(), Time: event.OccurredAt()}
+
+	switch e := event.(type) {
+	case domain.AccountCreated:
+		output.AccountID = e.AccountID
+		output.ActiveCard = &e.Ledger.ActiveCard
+		output.MaxLimit = &e.Ledger.MaxLimit
+		output.AvailableLimit = &e.Ledger.AvailableLimit
+		output.Rules = make([]EventRule, 0, len(e.Rules))
+
+		for _, rule := range e.Rules {
+			eventRule := EventRule{
+				Name:          rule.Name,
+				Type:          rule.Type,
+				UsageLimit:    rule.UsageLimit,
+				RuleViolation: rule.RuleViolation,
+			}
+
+			if rule.Accumulator != nil {
+				eventRule.Duration = rule.Accumulator.Duration.String()
+			}
+
+			output.Rules = append(output.Rules, eventRule)
+		}
+	case domain.TransactionApproved:
+		output.AccountID = e.AccountID
+		output.AuthorizationID = e.AuthorizationID
+		output.Merchant = e.Merchant
+		output.Amount = e.Amount
+		output.AvailableLimit = &e.AvailableLimit
+	case domain.TransactionDeclined:
+		output.AccountID = e.AccountID
+		output.AuthorizationID = e.AuthorizationID
+		output.Merchant = e.Merchant
+		output.Amount = e.Amount
+		output.AvailableLimit = &e.AvailableLimit
+		output.Violations = e.Violations
+	case domain.RuleTriggered:
+		output.AccountID = e.AccountID
+		output.AuthorizationID = e.AuthorizationID
+		output.Rule = e.Rule
+		output.Violation = e.Violation
+	}
+
+	return output
+}
+
+// BuildDomainEvent rebuild the domain event from its JSON form
+func BuildDomainEvent(e Event) (domain.Event, error) {
+	switch e.Type {
+	case domain.AccountCreatedEvent:
+		rules := make([]domain.Rule, 0, len(e.Rules))
+
+		for _, rule := range e.Rules {
+			domainRule := domain.Rule{
+				Name:          rule.Name,
+				Type:          rule.Type,
+				UsageLimit:    rule.UsageLimit,
+				RuleViolation: rule.RuleViolation,
+			}
+
+			if rule.Duration != "" {
+				duration, err := time.ParseDuration(rule.Duration)
+				if err != nil {
+					return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+				}
+
+				domainRule.Accumulator = &domain.Accumulator{Duration: duration}
+			}
+
+			rules = append(rules, domainRule)
+		}
+
+		return domain.AccountCreated{
+			AccountID: e.AccountID,
+			Ledger: domain.Ledger{
+				ActiveCard:     boolValue(e.ActiveCard),
+				MaxLimit:       int64Value(e.MaxLimit),
+				AvailableLimit: int64Value(e.AvailableLimit),
+			},
+			Rules: rules,
+			Time:  e.Time,
+		}, nil
+	case domain.TransactionApprovedEvent:
+		return domain.TransactionApproved{
+			AccountID:       e.AccountID,
+			AuthorizationID: e.AuthorizationID,
+			Merchant:        e.Merchant,
+			Amount:          e.Amount,
+			AvailableLimit:  int64Value(e.AvailableLimit),
+			Time:            e.Time,
+		}, nil
+	case domain.TransactionDeclinedEvent:
+		return domain.TransactionDeclined{
+			AccountID:       e.AccountID,
+			AuthorizationID: e.AuthorizationID,
+			Merchant:        e.Merchant,
+			Amount:          e.Amount,
+			AvailableLimit:  int64Value(e.AvailableLimit),
+			Violations:      e.Violations,
+			Time:            e.Time,
+		}, nil
+	case domain.RuleTriggeredEvent:
+		return domain.RuleTriggered{
+			AccountID:       e.AccountID,
+			AuthorizationID: e.AuthorizationID,
+			Rule:            e.Rule,
+			Violation:       e.Violation,
+			Time:            e.Time,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown event type %q", e.Type)
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
+
+func int64Value(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+
+	return *i
+}
@@ -0,0 +1,91 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent_RoundTrip(t *testing.T) {
+	eventTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name  string
+		event domain.Event
+	}{
+		{
+			name: "conta criada",
+			event: domain.AccountCreated{
+				AccountID: 1,
+				Ledger:    domain.Ledger{ActiveCard: true, MaxLimit: 100, AvailableLimit: 100},
+				Rules: []domain.Rule{
+					{
+						Name:          "max transactions in 2 minutes",
+						Type:          "usage-limit",
+						UsageLimit:    3,
+						Accumulator:   &domain.Accumulator{Duration: 2 * time.Minute},
+						RuleViolation: "high-frequency-small-interval",
+					},
+				},
+				Time: eventTime,
+			},
+		},
+		{
+			name: "transação aprovada sem limite disponível",
+			event: domain.TransactionApproved{
+				AccountID:       1,
+				AuthorizationID: "00000000000000000001",
+				Merchant:        "Vivara",
+				Amount:          100,
+				AvailableLimit:  0,
+				Time:            eventTime,
+			},
+		},
+		{
+			name: "transação recusada",
+			event: domain.TransactionDeclined{
+				AccountID:       1,
+				AuthorizationID: "00000000000000000002",
+				Merchant:        "Vivara",
+				Amount:          100,
+				AvailableLimit:  0,
+				Violations:      domain.Violations{domain.InsufficientLimitViolation},
+				Time:            eventTime,
+			},
+		},
+		{
+			name: "regra acionada",
+			event: domain.RuleTriggered{
+				AccountID:       1,
+				AuthorizationID: "00000000000000000002",
+				Rule:            "max transactions in 2 minutes",
+				Violation:       "high-frequency-small-interval",
+				Time:            eventTime,
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			jm, err := json.Marshal(NewEvent(tt.event))
+			assert.NoError(t, err)
+
+			var e Event
+			assert.NoError(t, json.Unmarshal(jm, &e))
+
+			event, err := BuildDomainEvent(e)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.event, event)
+		})
+	}
+}
+
+func TestBuildDomainEvent_Unknown_Type(t *testing.T) {
+	_, err := BuildDomainEvent(Event{Type: "AccountClosed"})
+
+	assert.EqualError(t, err, "unknown event type \"AccountClosed\"")
+}
@@ -1,6 +1,16 @@
 package dto
 
 type Input struct {
-	Account     *AccountOperation     `json:"account,omitempty"`
-	Transaction *TransactionOperation `json:"transaction,omitempty"`
+	IdempotencyKey     string                       `json:"idempotency-key,omitempty"`
+	Account            *AccountOperation            `json:"account,omitempty"`
+	Transaction        *TransactionOperation        `json:"transaction,omitempty"`
+	GetAuthorization   *GetAuthorizationOperation   `json:"get-authorization,omitempty"`
+	GetAccount         *GetAccountOperation         `json:"get-account,omitempty"`
+	ListAuthorizations *ListAuthorizationsOperation `json:"list-authorizations,omitempty"`
+}
+
+// InputError reports a line of the input that could not be decoded
+type InputError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
 }
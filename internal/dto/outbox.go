@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// OutboxEntry is the stored form of an outbox entry, the ID is the record key
+type OutboxEntry struct {
+	Event         Event     `json:"event"`
+	CreatedAt     time.Time `json:"created_at"`
+	Attempts      int       `json:"attempts"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+	LastError     string    `json:"last_error"`
+	DeliveredAt   time.Time `json:"delivered_at"`
+}
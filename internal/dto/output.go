@@ -1,11 +1,195 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+)
+
 type AccountOutput struct {
-	ActiveCard     *bool  `json:"active-card,omitempty"`
-	AvailableLimit *int64 `json:"available-limit,omitempty"`
+	ActiveCard     *bool        `json:"active-card,omitempty"`
+	AvailableLimit *int64       `json:"available-limit,omitempty"`
+	MaxLimit       *int64       `json:"max-limit,omitempty"`
+	Rules          []RuleOutput `json:"rules,omitempty"`
+}
+
+// RuleOutput is a spending control rule with its accumulator, only sent in the full account view
+type RuleOutput struct {
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	UsageLimit  int64     `json:"usage-limit"`
+	PeriodUsed  int64     `json:"period-used"`
+	PeriodSpend int64     `json:"period-spend"`
+	PeriodEnds  time.Time `json:"period-ends"`
+	Remaining   int64     `json:"remaining"`
+}
+
+// CheckOutput explains one check of a decision, only sent in verbose mode
+type CheckOutput struct {
+	Name      string                 `json:"name"`
+	Result    string                 `json:"result"`
+	Violation string                 `json:"violation,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+}
+
+type AuthorizationOutput struct {
+	ID             string    `json:"id"`
+	Merchant       string    `json:"merchant"`
+	Amount         int64     `json:"amount"`
+	Time           time.Time `json:"time"`
+	AvailableLimit int64     `json:"available-limit"`
+	Violations     []string  `json:"violations"`
+}
+
+// HistoryOutput is a page of the authorization history
+type HistoryOutput struct {
+	Authorizations []AuthorizationOutput `json:"authorizations"`
+	Total          int                   `json:"total"`
+	Offset         int                   `json:"offset"`
+	Limit          int                   `json:"limit"`
 }
 
 type Output struct {
-	Account    AccountOutput `json:"account"`
-	Violations []string      `json:"violations"`
+	Account         AccountOutput        `json:"account"`
+	Authorization   *AuthorizationOutput `json:"authorization,omitempty"`
+	History         *HistoryOutput       `json:"history,omitempty"`
+	Violations      []string             `json:"violations"`
+	AuthorizationID string               `json:"authorization-id,omitempty"`
+	Checks          []CheckOutput        `json:"checks,omitempty"`
+	Error           string               `json:"error,omitempty"`
+}
+
+// NewOutput build the Output every driver answers with
+func NewOutput(account *domain.Account, violations []string) Output {
+	output := Output{
+		Violations: violations,
+	}
+
+	if account != nil {
+		output.Account = AccountOutput{
+			ActiveCard:     &account.Ledger.ActiveCard,
+			AvailableLimit: &account.Ledger.AvailableLimit,
+		}
+	}
+
+	return output
+}
+
+// NewDecisionOutput build the Output for an authorization, listing its checks when verbose
+func NewDecisionOutput(decision domain.Decision, verbose bool) Output {
+	output := NewOutput(decision.Account, decision.Violations)
+	output.AuthorizationID = decision.AuthorizationID
+
+	if !verbose {
+		return output
+	}
+
+	output.Checks = make([]CheckOutput, 0, len(decision.Checks))
+
+	for _, check := range decision.Checks {
+		result := "passed"
+		if !check.Passed {
+			result = "failed"
+		}
+
+		output.Checks = append(output.Checks, CheckOutput{
+			Name:      check.Name,
+			Result:    result,
+			Violation: check.Violation,
+			Details:   check.Details,
+		})
+	}
+
+	return output
+}
+
+// NewAuthorizationOutput build the Output for an authorization lookup
+func NewAuthorizationOutput(authorization *domain.TransactionAuthorization, violations []string) Output {
+	output := NewOutput(nil, violations)
+
+	if authorization != nil {
+		authorizationOutput := buildAuthorizationOutput(*authorization)
+		output.Authorization = &authorizationOutput
+	}
+
+	return output
+}
+
+// NewAccountViewOutput build the Output with the full account view: the ledger and every
+// rule with its accumulator and remaining quota
+func NewAccountViewOutput(account *domain.Account, violations []string) Output {
+	output := NewOutput(account, violations)
+
+	if account == nil {
+		return output
+	}
+
+	output.Account.MaxLimit = &account.Ledger.MaxLimit
+	output.Account.Rules = make([]RuleOutput, 0, len(account.SpendingControl.Rules))
+
+	for _, rule := range account.SpendingControl.Rules {
+		ruleOutput := RuleOutput{
+			Name:       rule.Name,
+			Type:       rule.Type,
+			UsageLimit: rule.UsageLimit,
+			Remaining:  rule.Remaining(),
+		}
+
+		if rule.Accumulator != nil {
+			ruleOutput.PeriodUsed = rule.Accumulator.CurrentPeriodUsed
+			ruleOutput.PeriodSpend = rule.Accumulator.CurrentPeriodSpend
+			ruleOutput.PeriodEnds = rule.Accumulator.PeriodEndsDate
+		}
+
+		output.Account.Rules = append(output.Account.Rules, ruleOutput)
+	}
+
+	return output
+}
+
+// NewHistoryOutput build the Output for a page of the authorization history
+func NewHistoryOutput(page ports.AuthorizationPage, violations []string) Output {
+	output := NewOutput(nil, violations)
+
+	if len(violations) > 0 {
+		return output
+	}
+
+	output.History = &HistoryOutput{
+		Authorizations: make([]AuthorizationOutput, 0, len(page.Authorizations)),
+		Total:          page.Total,
+		Offset:         page.Offset,
+		Limit:          page.Limit,
+	}
+
+	for _, authorization := range page.Authorizations {
+		output.History.Authorizations = append(output.History.Authorizations, buildAuthorizationOutput(authorization))
+	}
+
+	return output
+}
+
+func buildAuthorizationOutput(authorization domain.TransactionAuthorization) AuthorizationOutput {
+	violations := []string(authorization.Violations)
+	if violations == nil {
+		violations = []string{}
+	}
+
+	return AuthorizationOutput{
+		ID:             authorization.ID,
+		Merchant:       authorization.Merchant,
+		Amount:         authorization.Amount,
+		Time:           authorization.Time,
+		AvailableLimit: authorization.AvailableLimit,
+		Violations:     violations,
+	}
+}
+
+// NewErrorOutput build the Output for operations that failed for internal reasons
+func NewErrorOutput(err error) Output {
+	return Output{
+		Violations: []string{domain.SystemErrorViolation},
+		Error:      err.Error(),
+	}
 }
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewErrorOutput(t *testing.T) {
+	output := NewErrorOutput(&ports.StorageError{Op: "retrieve", Err: errors.New("connection refused")})
+
+	jm, _ := json.Marshal(output)
+
+	assert.Equal(t, "{\"account\":{},\"violations\":[\"system-error\"],\"error\":\"storage error on retrieve: connection refused\"}", string(jm))
+}
+
+func TestNewDecisionOutput(t *testing.T) {
+	decision := domain.Decision{
+		Account:    &domain.Account{Ledger: domain.Ledger{ActiveCard: true, AvailableLimit: 10}},
+		Violations: domain.Violations{domain.InsufficientLimitViolation},
+		Checks: []domain.Check{
+			domain.NewCheck(domain.ActiveCardCheck, "", map[string]interface{}{"active-card": true}),
+			domain.NewCheck(domain.LedgerCheck, domain.InsufficientLimitViolation, map[string]interface{}{"remaining": int64(-10)}),
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		verbose  bool
+		expected string
+	}{
+		{
+			name:     "saída sem detalhes",
+			verbose:  false,
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":10},\"violations\":[\"insufficient-limit\"]}",
+		},
+		{
+			name:     "saída detalhada",
+			verbose:  true,
+			expected: "{\"account\":{\"active-card\":true,\"available-limit\":10},\"violations\":[\"insufficient-limit\"],\"checks\":[{\"name\":\"active-card\",\"result\":\"passed\",\"details\":{\"active-card\":true}},{\"name\":\"ledger\",\"result\":\"failed\",\"violation\":\"insufficient-limit\",\"details\":{\"remaining\":-10}}]}",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			jm, _ := json.Marshal(NewDecisionOutput(decision, tt.verbose))
+
+			assert.Equal(t, tt.expected, string(jm))
+		})
+	}
+}
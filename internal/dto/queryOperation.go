@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+type GetAccountOperation struct {
+	At time.Time `json:"at"`
+}
+
+type ListAuthorizationsOperation struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Merchant string    `json:"merchant"`
+	Offset   int       `json:"offset"`
+	Limit    int       `json:"limit"`
+}
@@ -0,0 +1,71 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+)
+
+// RuleConfig is a spending control rule as written in a rules configuration file
+type RuleConfig struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	UsageLimit    int64  `json:"usage-limit"`
+	Duration      string `json:"duration"`
+	RuleViolation string `json:"rule-violation"`
+}
+
+// RulesConfig is the spending control accounts are created with
+type RulesConfig struct {
+	Rules []RuleConfig `json:"rules"`
+}
+
+// BuildDomainRules validate the configuration and build the rules it describes, the error
+// names the first rule found invalid
+func BuildDomainRules(config RulesConfig) ([]domain.Rule, error) {
+	rules := make([]domain.Rule, 0, len(config.Rules))
+	names := make(map[string]bool, len(config.Rules))
+
+	for i, rule := range config.Rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i+1)
+		}
+
+		if domain.IsBuiltInCheck(rule.Name) {
+			return nil, fmt.Errorf("rule %q: name is reserved for a built-in check", rule.Name)
+		}
+
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule %q: name is used by another rule", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if rule.Type != domain.UsageLimitRule {
+			return nil, fmt.Errorf("rule %q: unknown type %q", rule.Name, rule.Type)
+		}
+
+		if rule.UsageLimit < 0 {
+			return nil, fmt.Errorf("rule %q: usage-limit must not be negative", rule.Name)
+		}
+
+		duration, err := time.ParseDuration(rule.Duration)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("rule %q: duration must be a positive duration such as \"2m\"", rule.Name)
+		}
+
+		if rule.RuleViolation == "" {
+			return nil, fmt.Errorf("rule %q: rule-violation is required", rule.Name)
+		}
+
+		rules = append(rules, domain.Rule{
+			Name:          rule.Name,
+			Type:          rule.Type,
+			UsageLimit:    rule.UsageLimit,
+			Accumulator:   &domain.Accumulator{Duration: duration},
+			RuleViolation: rule.RuleViolation,
+		})
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,93 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDomainRules(t *testing.T) {
+	valid := RuleConfig{Name: "max transactions in 1 minute", Type: "usage-limit", UsageLimit: 2, Duration: "1m", RuleViolation: "high-frequency-small-interval"}
+
+	testCases := []struct {
+		name        string
+		config      RulesConfig
+		expected    []domain.Rule
+		expectedErr string
+	}{
+		{
+			name:   "configuração válida",
+			config: RulesConfig{Rules: []RuleConfig{valid}},
+			expected: []domain.Rule{
+				{
+					Name:          "max transactions in 1 minute",
+					Type:          "usage-limit",
+					UsageLimit:    2,
+					Accumulator:   &domain.Accumulator{Duration: time.Minute},
+					RuleViolation: "high-frequency-small-interval",
+				},
+			},
+		},
+		{
+			name:     "configuração sem regras",
+			config:   RulesConfig{},
+			expected: []domain.Rule{},
+		},
+		{
+			name:        "regra sem nome",
+			config:      RulesConfig{Rules: []RuleConfig{{Type: "usage-limit", Duration: "1m", RuleViolation: "x"}}},
+			expectedErr: "rule 1: name is required",
+		},
+		{
+			name:        "regras com o mesmo nome",
+			config:      RulesConfig{Rules: []RuleConfig{valid, valid}},
+			expectedErr: "rule \"max transactions in 1 minute\": name is used by another rule",
+		},
+		{
+			name:        "regra com nome de verificação padrão",
+			config:      RulesConfig{Rules: []RuleConfig{{Name: domain.LedgerCheck, Type: "usage-limit", UsageLimit: 1, Duration: "1m", RuleViolation: "x"}}},
+			expectedErr: "rule \"ledger\": name is reserved for a built-in check",
+		},
+		{
+			name:        "regra com nome da verificação de duplicidade",
+			config:      RulesConfig{Rules: []RuleConfig{{Name: domain.DoubledTransactionCheck, Type: "usage-limit", UsageLimit: 1, Duration: "1m", RuleViolation: "x"}}},
+			expectedErr: "rule \"doubled-transaction\": name is reserved for a built-in check",
+		},
+		{
+			name:        "tipo desconhecido",
+			config:      RulesConfig{Rules: []RuleConfig{{Name: "a", Type: "amount-limit", Duration: "1m", RuleViolation: "x"}}},
+			expectedErr: "rule \"a\": unknown type \"amount-limit\"",
+		},
+		{
+			name:        "limite negativo",
+			config:      RulesConfig{Rules: []RuleConfig{{Name: "a", Type: "usage-limit", UsageLimit: -1, Duration: "1m", RuleViolation: "x"}}},
+			expectedErr: "rule \"a\": usage-limit must not be negative",
+		},
+		{
+			name:        "duração inválida",
+			config:      RulesConfig{Rules: []RuleConfig{{Name: "a", Type: "usage-limit", Duration: "0s", RuleViolation: "x"}}},
+			expectedErr: "rule \"a\": duration must be a positive duration such as \"2m\"",
+		},
+		{
+			name:        "regra sem violação",
+			config:      RulesConfig{Rules: []RuleConfig{{Name: "a", Type: "usage-limit", Duration: "1m"}}},
+			expectedErr: "rule \"a\": rule-violation is required",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := BuildDomainRules(tt.config)
+
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, rules)
+		})
+	}
+}
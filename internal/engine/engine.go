@@ -0,0 +1,285 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/clock"
+	"github.com/authorizer/internal/driven/database"
+	"github.com/authorizer/internal/driven/event"
+	"github.com/authorizer/internal/driven/identifier"
+	"github.com/authorizer/internal/driven/lock"
+	"github.com/authorizer/internal/driven/logging"
+	"github.com/authorizer/internal/driven/metrics"
+	"github.com/authorizer/internal/driven/repository"
+)
+
+// Storage backends the account can be kept in
+const (
+	MemoryStorage       = "memory"
+	DatabaseStorage     = "database"
+	EventSourcedStorage = "event-sourced"
+)
+
+// Orderings a transaction timestamped before the latest approved one can be handled under
+const (
+	// ArrivalOrdering evaluates every transaction as it arrives, however late
+	ArrivalOrdering = "arrival"
+	// RejectOrdering declines with transaction-out-of-order the transactions further behind
+	// than the lateness
+	RejectOrdering = "reject"
+	// ReorderOrdering holds transactions for the reorder window and processes them in time
+	// order, those later than the window are then handled as under RejectOrdering
+	ReorderOrdering = "reorder"
+)
+
+// ErrReorderUnsupported is returned for drivers answering every operation as it comes, which
+// cannot hold transactions to reorder them
+var ErrReorderUnsupported = errors.New("the reorder ordering needs a stream of operations")
+
+// IdempotencyWindow is how long the response to an operation sent with an idempotency key is kept
+const IdempotencyWindow = 24 * time.Hour
+
+// Outbox relay settings: how often it delivers, how many entries it reads at a time and how
+// long delivered entries are kept
+const (
+	RelayInterval   = 100 * time.Millisecond
+	RelayBatchSize  = 100
+	OutboxRetention = time.Hour
+)
+
+// Options describe the engine the binaries run, most of them are set from flags
+type Options struct {
+	Storage          string
+	SnapshotInterval int
+	RulesPath        string
+	Ordering         string
+	Lateness         time.Duration
+	Reorder          time.Duration
+	Retention        time.Duration
+	Events           string
+	Log              LogOptions
+
+	// Rules are the rules accounts are created with, RulesPath is read when they are nil
+	Rules []domain.Rule
+	// SequentialIDs numbers the authorizations from 1 instead of stamping ULIDs, so two runs
+	// over the same input answer the same IDs
+	SequentialIDs bool
+}
+
+// Register add every engine flag to flags
+func (o *Options) Register(flags *flag.FlagSet) {
+	o.RegisterEvaluation(flags)
+	flags.StringVar(&o.RulesPath, "rules", "", "rules configuration accounts are created with, the default rules when empty")
+	flags.StringVar(&o.Events, "events", "", "file the domain events are appended to, one JSON per line")
+	o.Log.Register(flags)
+}
+
+// RegisterEvaluation add the flags that change how transactions are evaluated, for the commands
+// that take the rules some other way and deliver no event
+func (o *Options) RegisterEvaluation(flags *flag.FlagSet) {
+	flags.StringVar(&o.Storage, "storage", MemoryStorage, "where the account is kept, memory, database or event-sourced")
+	flags.IntVar(&o.SnapshotInterval, "snapshot-interval", repository.DefaultSnapshotInterval, "events between snapshots of the event-sourced storage")
+	flags.StringVar(&o.Ordering, "ordering", ArrivalOrdering, "how transactions older than the latest approved one are handled, arrival, reject or reorder")
+	flags.DurationVar(&o.Lateness, "lateness", 0, "how far behind the latest transaction one may be and still be evaluated, reject and reorder orderings")
+	flags.DurationVar(&o.Reorder, "reorder", 0, "how far past a transaction the input must be before it is processed, reorder ordering")
+	flags.DurationVar(&o.Retention, "retention", domain.DefaultRetention, "how long authorizations are kept in the history, in transaction time, 0 keeps them all")
+}
+
+// Engine is the authorizer the binaries run: the services over the chosen storage, the
+// metrics, the log and the delivery of the events, all reading the time from Clock. Reorder
+// is the window the drivers hold transactions for, zero unless the ordering is reorder
+type Engine struct {
+	Clock        ports.Clock
+	Rules        []domain.Rule
+	Reorder      time.Duration
+	Accounts     service.Account
+	Transactions service.Transaction
+	Idempotency  service.Idempotency
+	Metrics      *metrics.Metrics
+	Logger       *logging.Logger
+	Delivery     Delivery
+	CloseLog     func() error
+}
+
+// Build wire the engine the options describe, its CloseLog releases the log sink
+func (o Options) Build() (*Engine, error) {
+	rules, err := o.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	ordering, err := o.ordering()
+	if err != nil {
+		return nil, err
+	}
+
+	systemClock := clock.NewSystemClock()
+
+	accountRepo, authorizationRepo, outbox, err := o.accountStorage(systemClock)
+	if err != nil {
+		return nil, err
+	}
+
+	m := metrics.NewMetrics()
+	locker := lock.NewInMemoryLocker()
+	retention := domain.RetentionPolicy{MaxAge: o.Retention}
+
+	var ids ports.IDGenerator = identifier.NewULIDGenerator(systemClock)
+	if o.SequentialIDs {
+		ids = identifier.NewSequentialGenerator()
+	}
+
+	instrumentedAccountRepo := metrics.NewAccountRepository(accountRepo, m, systemClock)
+	instrumentedAuthorizationRepo := metrics.NewAuthorizationRepository(authorizationRepo, m, systemClock)
+
+	ts := service.NewTransaction(instrumentedAccountRepo, instrumentedAuthorizationRepo, locker, ids, outbox, retention).
+		WithOrdering(ordering).
+		WithObservers(systemClock, m)
+
+	logger, ts, closeLog, err := o.Log.open(systemClock, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	bus := event.NewBus()
+
+	return &Engine{
+		Clock:        systemClock,
+		Rules:        rules,
+		Reorder:      o.Reorder,
+		Accounts:     service.NewAccountWithRules(instrumentedAccountRepo, locker, systemClock, rules),
+		Transactions: ts,
+		Idempotency:  service.NewIdempotency(repository.NewIdempotencyRepository(), systemClock, IdempotencyWindow),
+		Metrics:      m,
+		Logger:       logger,
+		Delivery: Delivery{
+			bus:    bus,
+			relay:  service.NewRelay(outbox, bus, systemClock, RelayBatchSize, OutboxRetention),
+			logger: logger,
+		},
+		CloseLog: closeLog,
+	}, nil
+}
+
+// rules return the rules accounts are created with: Rules when set, otherwise the
+// configuration at RulesPath or the default rules
+func (o Options) rules() ([]domain.Rule, error) {
+	if o.Rules != nil {
+		return o.Rules, nil
+	}
+
+	if o.RulesPath == "" {
+		return domain.DefaultRules(), nil
+	}
+
+	return LoadRules(o.RulesPath)
+}
+
+// ordering return the policy the transaction service runs under. An empty ordering is
+// ArrivalOrdering, the lateness and the reorder window only go with the orderings using them
+func (o Options) ordering() (domain.OrderingPolicy, error) {
+	switch o.Ordering {
+	case "", ArrivalOrdering:
+		if o.Lateness != 0 || o.Reorder != 0 {
+			return domain.OrderingPolicy{}, errors.New("lateness and reorder need the reject or reorder ordering")
+		}
+
+		return domain.OrderingPolicy{AnyLateness: true}, nil
+	case RejectOrdering:
+		if o.Reorder != 0 {
+			return domain.OrderingPolicy{}, errors.New("reorder needs the reorder ordering")
+		}
+
+		return domain.OrderingPolicy{Lateness: o.Lateness}, nil
+	case ReorderOrdering:
+		if o.Reorder <= 0 {
+			return domain.OrderingPolicy{}, fmt.Errorf("reorder window must be positive, got %s", o.Reorder)
+		}
+
+		return domain.OrderingPolicy{Lateness: o.Lateness}, nil
+	default:
+		return domain.OrderingPolicy{}, fmt.Errorf("unknown ordering %q", o.Ordering)
+	}
+}
+
+// RefuseReorder return ErrReorderUnsupported, naming the driver, when the engine reorders
+// transactions, drivers answering every operation as it comes call it before they start
+func (e *Engine) RefuseReorder(driver string) error {
+	if e.Reorder > 0 {
+		return fmt.Errorf("%s: %w", driver, ErrReorderUnsupported)
+	}
+
+	return nil
+}
+
+// accountStorage return the account repository of the chosen backend with the authorization
+// history and the outbox its events are stored in, stamped on the clock. The event-sourced
+// storage projects the history from its stream, the database one moves the authorizations kept
+// inside older stored accounts to it
+func (o Options) accountStorage(c ports.Clock) (ports.AccountRepository, ports.AuthorizationRepository, ports.Outbox, error) {
+	switch o.Storage {
+	case MemoryStorage:
+		accountRepo := repository.NewInMemoryAccountRepository(c)
+		return accountRepo, repository.NewAuthorizationRepository(), accountRepo.Outbox(), nil
+	case DatabaseStorage:
+		db := database.NewInMemoryDB()
+		history := repository.NewAuthorizationRepository()
+		return repository.NewAccountRepository(db, history, c), history, repository.NewOutboxRepository(db, c), nil
+	case EventSourcedStorage:
+		if o.SnapshotInterval <= 0 {
+			return nil, nil, nil, fmt.Errorf("snapshot interval must be positive, got %d", o.SnapshotInterval)
+		}
+
+		accountRepo := repository.NewEventSourcedAccountRepository(c, o.SnapshotInterval)
+		return accountRepo, accountRepo.History(), accountRepo, nil
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown storage %q", o.Storage)
+	}
+}
+
+// Delivery carries the events stored in the outbox to the bus
+type Delivery struct {
+	bus    *event.Bus
+	relay  service.Relay
+	logger *logging.Logger
+}
+
+// Start subscribe a sink appending every event to the file at path, when there is one, and
+// run the relay in the background. stop delivers what is left, then closes the file
+func (d Delivery) Start(path string) (stop func(), err error) {
+	closeSink := func() error { return nil }
+
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+
+		d.bus.SubscribeAll(event.NewNDJSONSink(f).Handle)
+		closeSink = f.Close
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		d.relay.Run(ctx, RelayInterval, func(err error) {
+			d.logger.Error("relay delivery failed", logging.Field{Key: "error", Value: err})
+		})
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		_ = closeSink()
+	}, nil
+}
@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_Build(t *testing.T) {
+	baseTime := time.Date(2021, 10, 10, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		options            Options
+		expectedViolations domain.Violations
+	}{
+		{
+			name:               "transação atrasada na ordem de chegada",
+			options:            Options{Storage: MemoryStorage},
+			expectedViolations: domain.Violations{},
+		},
+		{
+			name:               "transação atrasada sem tolerância",
+			options:            Options{Storage: MemoryStorage, Ordering: RejectOrdering},
+			expectedViolations: domain.Violations{domain.TransactionOutOfOrderViolation},
+		},
+		{
+			name:               "transação atrasada dentro da tolerância",
+			options:            Options{Storage: MemoryStorage, Ordering: RejectOrdering, Lateness: time.Minute},
+			expectedViolations: domain.Violations{},
+		},
+		{
+			name:               "transação atrasada além da janela de reordenação",
+			options:            Options{Storage: MemoryStorage, Ordering: ReorderOrdering, Reorder: 10 * time.Second},
+			expectedViolations: domain.Violations{domain.TransactionOutOfOrderViolation},
+		},
+		{
+			name:               "transação atrasada no armazenamento por eventos",
+			options:            Options{Storage: EventSourcedStorage, SnapshotInterval: 2, Ordering: RejectOrdering, Lateness: time.Minute},
+			expectedViolations: domain.Violations{},
+		},
+		{
+			name:               "transação atrasada no armazenamento em banco",
+			options:            Options{Storage: DatabaseStorage, Ordering: RejectOrdering, Lateness: time.Minute},
+			expectedViolations: domain.Violations{},
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.SequentialIDs = true
+
+			e, err := tt.options.Build()
+			assert.NoError(t, err)
+
+			_, _, _ = e.Accounts.InitAccount(true, 100)
+			_, _ = e.Transactions.Authorize(domain.Transaction{Merchant: "Burger King", Amount: 10, Time: baseTime})
+
+			decision, err := e.Transactions.Authorize(domain.Transaction{Merchant: "Habbib's", Amount: 10, Time: baseTime.Add(-30 * time.Second)})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedViolations, decision.Violations)
+			assert.Equal(t, "00000000000000000002", decision.AuthorizationID)
+		})
+	}
+}
+
+func TestOptions_Build_Rules(t *testing.T) {
+	rules := []domain.Rule{
+		{Name: "max 1 transaction in 1 minute", Type: domain.UsageLimitRule, UsageLimit: 1, Accumulator: &domain.Accumulator{Duration: time.Minute}, RuleViolation: "velocity-exceeded"},
+	}
+
+	e, err := Options{Storage: MemoryStorage}.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, domain.DefaultRules(), e.Rules)
+
+	e, err = Options{Storage: MemoryStorage, RulesPath: "missing.json", Rules: rules}.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, rules, e.Rules, "rules given take the place of the configuration file")
+}
+
+func TestEngine_RefuseReorder(t *testing.T) {
+	testCases := []struct {
+		name        string
+		options     Options
+		expectedErr error
+	}{
+		{name: "ordem de chegada", options: Options{Storage: MemoryStorage}, expectedErr: nil},
+		{name: "recusa de transações atrasadas", options: Options{Storage: MemoryStorage, Ordering: RejectOrdering}, expectedErr: nil},
+		{name: "reordenação", options: Options{Storage: MemoryStorage, Ordering: ReorderOrdering, Reorder: time.Minute}, expectedErr: ErrReorderUnsupported},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := tt.options.Build()
+			assert.NoError(t, err)
+
+			err = e.RefuseReorder("http")
+
+			if tt.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestOptions_Build_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+	}{
+		{name: "armazenamento desconhecido", options: Options{Storage: "disk"}},
+		{name: "intervalo de snapshot inválido", options: Options{Storage: EventSourcedStorage}},
+		{name: "arquivo de regras inexistente", options: Options{Storage: MemoryStorage, RulesPath: "missing.json"}},
+		{name: "nível de log desconhecido", options: Options{Storage: MemoryStorage, Log: LogOptions{Level: "verbose"}}},
+		{name: "ordenação desconhecida", options: Options{Storage: MemoryStorage, Ordering: "sorted"}},
+		{name: "tolerância na ordem de chegada", options: Options{Storage: MemoryStorage, Lateness: time.Minute}},
+		{name: "janela de reordenação sem reordenar", options: Options{Storage: MemoryStorage, Ordering: RejectOrdering, Reorder: time.Minute}},
+		{name: "reordenação sem janela", options: Options{Storage: MemoryStorage, Ordering: ReorderOrdering}},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.options.Build()
+
+			assert.Error(t, err)
+		})
+	}
+}
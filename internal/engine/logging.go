@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/authorizer/internal/core/ports"
+	"github.com/authorizer/internal/core/service"
+	"github.com/authorizer/internal/driven/logging"
+)
+
+// LogOptions configure the structured log: where records go, the lowest level written and the
+// share of approved decisions kept
+type LogOptions struct {
+	Path           string
+	Level          string
+	SampleApproved float64
+}
+
+// Register add the log flags to flags
+func (o *LogOptions) Register(flags *flag.FlagSet) {
+	flags.StringVar(&o.Path, "log", "", "file every authorization decision is logged to, one JSON per line, - for the standard error")
+	flags.StringVar(&o.Level, "log-level", "info", "lowest level logged, debug, info, warn or error")
+	flags.Float64Var(&o.SampleApproved, "log-sample-approved", 1, "share of the approved decisions logged, from 0 to 1")
+}
+
+// open point the logger at the configured sink and add the decision log to the transaction
+// service when there is one. Without a sink records keep going to the standard error and no
+// decision is logged. An empty level logs from info up. close releases the sink
+func (o LogOptions) open(c ports.Clock, ts service.Transaction) (*logging.Logger, service.Transaction, func() error, error) {
+	level := logging.InfoLevel
+
+	if o.Level != "" {
+		var err error
+		if level, err = logging.ParseLevel(o.Level); err != nil {
+			return nil, ts, nil, err
+		}
+	}
+
+	var (
+		w        io.Writer = os.Stderr
+		closeLog           = func() error { return nil }
+	)
+
+	if o.Path != "" && o.Path != "-" {
+		f, err := os.OpenFile(o.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, ts, nil, err
+		}
+
+		w, closeLog = f, f.Close
+	}
+
+	logger := logging.NewLogger(w, level, c)
+
+	if o.Path != "" {
+		ts = ts.WithObservers(c, logging.NewDecisionLog(logger, o.SampleApproved))
+	}
+
+	return logger, ts, closeLog, nil
+}
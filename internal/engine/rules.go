@@ -0,0 +1,42 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/authorizer/internal/core/domain"
+	"github.com/authorizer/internal/dto"
+)
+
+// LoadRules read and validate a rules configuration file
+func LoadRules(path string) ([]domain.Rule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return decodeRules(path, file)
+}
+
+// decodeRules decode a rules configuration, fields it does not know are refused so typos do not
+// go unnoticed
+func decodeRules(name string, r io.Reader) ([]domain.Rule, error) {
+	var config dto.RulesConfig
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	rules, err := dto.BuildDomainRules(config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return rules, nil
+}